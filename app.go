@@ -3,10 +3,15 @@ package main
 import (
 	"errors"
 	"fmt"
+	"ivan/autotracker"
 	"ivan/inputviewer"
+	"ivan/multiworld"
+	"ivan/theme"
 	"ivan/timer"
 	"ivan/tracker"
 	"log"
+	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/bep/debounce"
@@ -16,12 +21,16 @@ import (
 
 const configDir = "./config"
 
+var themesDir = filepath.Join(configDir, "themes")
+
 var errCloseApp = errors.New("user requested app close")
 
 type App struct {
 	tracker     *tracker.Tracker
 	timer       *timer.Timer
 	inputViewer *inputviewer.InputViewer
+	autoTracker *autotracker.AutoTracker
+	multiworld  *multiworld.Client
 	config      tracker.Config
 	lastSave    time.Time
 
@@ -38,12 +47,14 @@ func NewApp() (*App, error) {
 	ebiten.SetWindowSize(size.X, size.Y)
 	ebiten.SetWindowPosition(1920-size.X, 0)
 
-	timer, err := timer.New(cfg.Layout.Timer)
+	cfg.Theme.Name = theme.Selected(cfg.Theme.Name)
+	th, err := theme.Load(themesDir, cfg.Theme.Name)
 	if err != nil {
 		return nil, err
 	}
 
-	tracker, err := tracker.New(cfg)
+	timer := timer.New(cfg.Layout.Timer, th)
+	tracker, err := tracker.New(cfg, th)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +70,9 @@ func NewApp() (*App, error) {
 	return &App{
 		tracker:      tracker,
 		timer:        timer,
-		inputViewer:  nil, // initialized on first frame to ensure we have a gamepad
+		inputViewer:  inputviewer.NewInputViewer(cfg.InputViewer, cfg.Layout.InputHistory, th),
+		autoTracker:  autotracker.New(cfg.AutoTracker),
+		multiworld:   multiworld.New(cfg.Multiworld, tracker.ReceivedItemCount()),
 		config:       cfg,
 		saveDebounce: debounce.New(1 * time.Second),
 		lastSave:     time.Now(),
@@ -71,13 +84,30 @@ func (app *App) Update() error {
 	_, wheel := ebiten.Wheel()
 	var shouldSave bool
 
-	if app.inputViewer == nil && app.config.InputViewer.Enabled {
-		app.inputViewer = inputviewer.NewInputViewer(app.config.InputViewer)
+	app.tracker.Hover(ebiten.CursorPosition())
+	if files := ebiten.DroppedFiles(); files != nil {
+		app.tracker.DropFiles(files)
+		shouldSave = true
+	}
+
+	app.inputViewer.Update()
+	if states, ok := app.autoTracker.Poll(); ok && app.tracker.ApplyItemStates(states) {
+		shouldSave = true
+	}
+	changed, missing := app.tracker.ReceiveItems(app.multiworld.Poll())
+	if changed {
+		shouldSave = true
+	}
+	if missing {
+		app.multiworld.Reset()
 	}
 
 	switch {
 	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
 		if !app.timer.IsRunning() && app.tracker.IsIdle() {
+			app.inputViewer.StopRecording()
+			app.autoTracker.Close()
+			app.multiworld.Close()
 			return errCloseApp
 		}
 		app.tracker.Cancel()
@@ -97,12 +127,29 @@ func (app *App) Update() error {
 		if app.timer.CanReset() {
 			app.timer.Reset()
 			app.tracker.Reset()
+			app.inputViewer.ResetHistory()
+			app.autoTracker.Resend()
+			app.multiworld.Reset()
 			shouldSave = true
 		}
 
 	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
 		shouldSave = true
 
+	case inpututil.IsKeyJustPressed(ebiten.KeyF6):
+		app.inputViewer.ToggleCalibration(filepath.Join(configDir, "input_viewer.json"))
+
+	case inpututil.IsKeyJustPressed(ebiten.KeyF7):
+		if err := app.inputViewer.ToggleRecording(app.timer.Elapsed); err != nil {
+			log.Printf("error: %s", err)
+		}
+
+	case inpututil.IsKeyJustPressed(ebiten.KeyF8):
+		app.inputViewer.NextGamepad()
+
+	case inpututil.IsKeyJustPressed(ebiten.KeyF9):
+		app.nextTheme()
+
 	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
 		app.tracker.Backspace()
 
@@ -147,6 +194,34 @@ func (app *App) save() {
 	})
 }
 
+// nextTheme switches to the next theme of the themes directory and saves the
+// selection next to the tracker state.
+func (app *App) nextTheme() {
+	names, err := theme.List(themesDir)
+	if err != nil {
+		log.Printf("error: unable to list themes: %s", err)
+		return
+	}
+
+	current := slices.Index(names, app.config.Theme.Name)
+	name := names[(current+1)%len(names)]
+	th, err := theme.Load(themesDir, name)
+	if err != nil {
+		log.Printf("error: %s", err)
+		return
+	}
+
+	app.tracker.SetTheme(th)
+	app.timer.SetTheme(th)
+	app.inputViewer.SetTheme(th)
+	app.config.Theme.Name = name
+	log.Printf("info: theme '%s'", name)
+
+	if err := theme.SaveSelected(name); err != nil {
+		log.Printf("error: unable to write theme selection: %s", err)
+	}
+}
+
 func (app *App) Draw(screen *ebiten.Image) {
 	app.tracker.Draw(screen)
 	app.timer.Draw(screen)
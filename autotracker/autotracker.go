@@ -0,0 +1,188 @@
+// Package autotracker reads the OoT inventory from a running emulator or from
+// its save file.
+package autotracker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	Enabled bool
+
+	Protocol string // "RetroArch" or "SaveFile"
+	Address  string // host:port of the emulator
+
+	// Save file (.sra, .srm) watched by the SaveFile protocol and its slot,
+	// from 1 to 3.
+	SaveFile string
+	Slot     int
+
+	// Address of the save context in the emulator memory, eg. "0x8011A5D0",
+	// and whether the emulator stores the memory as little endian 32-bit
+	// words.
+	SaveContext string
+	WordSwap    bool
+
+	Interval int // between reads, in milliseconds
+}
+
+// Source reads the save context, in big endian.
+type Source interface {
+	ReadSaveContext() ([]byte, error)
+	Close() error
+}
+
+// AutoTracker polls its source in the background.
+type AutoTracker struct {
+	config  Config
+	updates chan map[string]ItemState
+	resend  chan struct{}
+	stop    chan struct{}
+}
+
+func newSource(config Config) (Source, error) {
+	switch config.Protocol {
+	case "RetroArch", "":
+		saveContext, err := strconv.ParseUint(config.SaveContext, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid save context address: %w", err)
+		}
+
+		return newRetroArch(config.Address, uint32(saveContext), config.WordSwap)
+	case "SaveFile":
+		return newSaveFile(config.SaveFile, config.Slot)
+	default:
+		return nil, errors.New("unknown auto-tracker protocol '" + config.Protocol + "'")
+	}
+}
+
+// New starts polling the emulator, it returns nil if the auto-tracker is
+// disabled or its source cannot be opened.
+func New(config Config) *AutoTracker {
+	if !config.Enabled {
+		return nil
+	}
+
+	source, err := newSource(config)
+	if err != nil {
+		log.Printf("error: auto-tracker disabled: %s", err)
+		return nil
+	}
+
+	at := &AutoTracker{
+		config:  config,
+		updates: make(chan map[string]ItemState, 1),
+		resend:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	go at.run(source)
+
+	return at
+}
+
+// Close stops polling the emulator.
+func (at *AutoTracker) Close() {
+	if at == nil {
+		return
+	}
+
+	close(at.stop)
+}
+
+// Resend sends the next read item states even if they did not change, eg.
+// after the tracker was reset.
+func (at *AutoTracker) Resend() {
+	if at == nil {
+		return
+	}
+
+	select {
+	case at.resend <- struct{}{}:
+	default:
+	}
+}
+
+// Poll returns the last read item states if they changed since the last call.
+func (at *AutoTracker) Poll() (map[string]ItemState, bool) {
+	if at == nil {
+		return nil, false
+	}
+
+	select {
+	case states := <-at.updates:
+		return states, true
+	default:
+		return nil, false
+	}
+}
+
+func (at *AutoTracker) run(source Source) {
+	defer source.Close()
+
+	interval := time.Duration(max(100, at.config.Interval)) * time.Millisecond
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		last    map[string]ItemState
+		failing bool
+	)
+
+	for {
+		select {
+		case <-at.stop:
+			return
+		case <-at.resend:
+			last = nil
+			continue
+		case <-ticker.C:
+		}
+
+		states, err := at.read(source)
+		if err != nil {
+			if !failing {
+				log.Printf("warning: auto-tracker: %s", err)
+				failing = true
+			}
+			continue
+		}
+
+		if failing {
+			log.Printf("info: auto-tracker: reading memory")
+			failing = false
+		}
+
+		if maps.Equal(states, last) {
+			continue
+		}
+		last = states
+
+		// Replace a pending update that was not polled yet.
+		select {
+		case <-at.updates:
+		default:
+		}
+		at.updates <- states
+	}
+}
+
+func (at *AutoTracker) read(source Source) (map[string]ItemState, error) {
+	mem, err := source.ReadSaveContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSaveContext(mem)
+}
+
+// swapWords reverses the bytes of every 32-bit word.
+func swapWords(mem []byte) {
+	for k := 0; k+4 <= len(mem); k += 4 {
+		mem[k], mem[k+1], mem[k+2], mem[k+3] = mem[k+3], mem[k+2], mem[k+1], mem[k]
+	}
+}
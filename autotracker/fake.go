@@ -0,0 +1,35 @@
+package autotracker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnswerReadCoreMemory replies to a RetroArch READ_CORE_MEMORY command like
+// the emulator would, from memory starting at the base address, to run the
+// auto-tracker against a fake emulator.
+func AnswerReadCoreMemory(cmd string, base uint32, read func() ([]byte, error)) string {
+	var addr uint32
+	var size int
+	if _, err := fmt.Sscanf(cmd, "READ_CORE_MEMORY %x %d", &addr, &size); err != nil {
+		return fmt.Sprintf("READ_CORE_MEMORY %x -1 invalid command", addr)
+	}
+
+	mem, err := read()
+	if err != nil {
+		return fmt.Sprintf("READ_CORE_MEMORY %x -1 %s", addr, err)
+	}
+
+	start := int(addr) - int(base)
+	if start < 0 || start+size > len(mem) {
+		return fmt.Sprintf("READ_CORE_MEMORY %x -1 address out of range", addr)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "READ_CORE_MEMORY %x", addr)
+	for _, v := range mem[start : start+size] {
+		fmt.Fprintf(&sb, " %02x", v)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+// Command fakeretroarch answers RetroArch READ_CORE_MEMORY commands from a
+// memory dump, to run the auto-tracker without an emulator. The dump is read
+// again on every command so it can be edited while the tracker runs.
+package main
+
+import (
+	"flag"
+	"ivan/autotracker"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:55355", "UDP address to listen on")
+	dump := flag.String("dump", "", "memory dump file")
+	base := flag.Uint64("base", 0x8011A5D0, "address of the first byte of the dump")
+	flag.Parse()
+
+	conn, err := net.ListenPacket("udp", *address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	log.Printf("listening on %s", conn.LocalAddr())
+
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		reply := autotracker.AnswerReadCoreMemory(strings.TrimSpace(string(buf[:n])), uint32(*base), func() ([]byte, error) {
+			return os.ReadFile(*dump)
+		})
+		if _, err := conn.WriteTo([]byte(reply+"\n"), from); err != nil {
+			log.Printf("error: %s", err)
+		}
+	}
+}
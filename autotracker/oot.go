@@ -0,0 +1,199 @@
+package autotracker
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Offsets in the OoT save context, see z64save.h.
+const (
+	saveContextSize = 0xD4
+
+	offsetNewf           = 0x1C
+	offsetMagicLevel     = 0x32
+	offsetMagicAcquired  = 0x3A
+	offsetDoubleMagic    = 0x3C
+	offsetItems          = 0x74
+	offsetEquipment      = 0x9C
+	offsetUpgrades       = 0xA0
+	offsetQuestItems     = 0xA4
+	offsetGoldSkulltulas = 0xD0
+
+	itemNone = 0xFF
+)
+
+// ItemState is the state of a tracker item as read from the game.
+type ItemState struct {
+	Enabled      bool
+	UpgradeIndex int
+	Count        int
+}
+
+// inventorySlots are the items owned when their inventory slot is not empty.
+var inventorySlots = map[int]string{
+	4:  "Fire Arrows",
+	5:  "Dins Fire",
+	8:  "Bombchu",
+	10: "Ice Arrows",
+	11: "Farores Wind",
+	12: "Boomerang",
+	13: "Lens of Truth",
+	14: "Magic Bean",
+	15: "Hammer",
+	16: "Light Arrows",
+	17: "Nayrus Love",
+}
+
+var equipmentBits = map[int]string{
+	0:  "Kokiri Sword",
+	1:  "Master Sword",
+	2:  "Biggoron Sword",
+	4:  "Deku Shield",
+	5:  "Hylian Shield",
+	6:  "Mirror Shield",
+	8:  "Kokiri Tunic",
+	9:  "Goron Tunic",
+	10: "Zora Tunic",
+	12: "Kokiri Boots",
+	13: "Iron Boots",
+	14: "Hover Boots",
+}
+
+var questBits = map[int]string{
+	0:  "Forest Medallion",
+	1:  "Fire Medallion",
+	2:  "Water Medallion",
+	3:  "Spirit Medallion",
+	4:  "Shadow Medallion",
+	5:  "Light Medallion",
+	6:  "Minuet of Forest",
+	7:  "Bolero of Fire",
+	8:  "Serenade of Water",
+	9:  "Requiem of Spirit",
+	10: "Nocturne of Shadow",
+	11: "Prelude of Light",
+	12: "Zeldas Lullaby",
+	13: "Eponas Song",
+	14: "Sarias Song",
+	15: "Suns Song",
+	16: "Song of Time",
+	17: "Song of Storms",
+	18: "Kokiri Emerald",
+	19: "Goron Ruby",
+	20: "Zora Sapphire",
+	21: "Stone of Agony",
+	22: "Gerudo Membership Card",
+}
+
+// capacityUpgrades are the items whose capacity is stored in the upgrades
+// bitfield, by bit offset, along with their inventory slot if they have one.
+var capacityUpgrades = []struct {
+	name  string
+	shift int
+	slot  int // -1 if the upgrade is the item
+}{
+	{"Bow", 0, 3},
+	{"Bomb Bag", 3, -1},
+	{"Slingshot", 14, 6},
+	{"Deku Stick", 17, 0},
+	{"Deku Nut", 20, 1},
+}
+
+// decodeSaveContext returns the state of the tracker items from the big
+// endian save context memory.
+func decodeSaveContext(mem []byte) (map[string]ItemState, error) {
+	if string(mem[offsetNewf:offsetNewf+6]) != "ZELDAZ" {
+		return nil, errors.New("no save file loaded")
+	}
+
+	var (
+		ret       = make(map[string]ItemState)
+		items     = mem[offsetItems : offsetItems+24]
+		equipment = binary.BigEndian.Uint16(mem[offsetEquipment:])
+		upgrades  = binary.BigEndian.Uint32(mem[offsetUpgrades:])
+		quest     = binary.BigEndian.Uint32(mem[offsetQuestItems:])
+	)
+
+	enable := func(name string, enabled bool) {
+		ret[name] = ItemState{Enabled: enabled}
+	}
+
+	for slot, name := range inventorySlots {
+		enable(name, items[slot] != itemNone)
+	}
+
+	for bit, name := range equipmentBits {
+		enable(name, equipment&(1<<bit) != 0)
+	}
+
+	for bit, name := range questBits {
+		enable(name, quest&(1<<bit) != 0)
+	}
+
+	for _, v := range capacityUpgrades {
+		level := int(upgrades>>v.shift) & 0x7
+		enabled := level > 0
+		if v.slot >= 0 {
+			enabled = items[v.slot] != itemNone
+		}
+		ret[v.name] = ItemState{Enabled: enabled, UpgradeIndex: max(0, level-1)}
+	}
+
+	upgrade := func(name string, level int) {
+		ret[name] = ItemState{Enabled: level > 0, UpgradeIndex: max(0, level-1)}
+	}
+	upgrade("Progressive Force", int(upgrades>>6)&0x7)
+	upgrade("Progressive Scale", int(upgrades>>9)&0x7)
+	ret["Wallet"] = ItemState{Enabled: true, UpgradeIndex: int(upgrades>>12) & 0x3}
+
+	// Item upgrades share a slot, the slot holds the best one.
+	switch items[7] {
+	case 0x07:
+		upgrade("Ocarina", 1)
+	case 0x08:
+		upgrade("Ocarina", 2)
+	default:
+		upgrade("Ocarina", 0)
+	}
+
+	switch items[9] {
+	case 0x0A:
+		upgrade("Progressive Hookshot", 1)
+	case 0x0B:
+		upgrade("Progressive Hookshot", 2)
+	default:
+		upgrade("Progressive Hookshot", 0)
+	}
+
+	switch {
+	case mem[offsetDoubleMagic] != 0:
+		upgrade("Magic Meter", 2)
+	case mem[offsetMagicAcquired] != 0 || mem[offsetMagicLevel] != 0:
+		upgrade("Magic Meter", 1)
+	default:
+		upgrade("Magic Meter", 0)
+	}
+
+	// Trade items are stored by ID, in the order of the tracker progression.
+	if id := items[23]; id >= 0x21 && id <= 0x2C {
+		upgrade("Mask Trade Sequence", int(id-0x21)+1)
+	}
+	if id := items[22]; id >= 0x2D && id <= 0x37 {
+		upgrade("Trade Sequence", int(id-0x2D)+1)
+	}
+
+	// Only bottles are tracked, not their content.
+	var letter bool
+	for k, slot := range items[18:22] {
+		if k < 3 {
+			enable("Bottle "+string(rune('1'+k)), slot != itemNone)
+		}
+		letter = letter || slot == 0x1B
+	}
+	enable("Rutos Letter", letter)
+
+	tokens := int(int16(binary.BigEndian.Uint16(mem[offsetGoldSkulltulas:])))
+	ret["Gold Skulltula Token"] = ItemState{Enabled: true, Count: max(0, tokens)}
+
+	return ret, nil
+}
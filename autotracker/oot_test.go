@@ -0,0 +1,94 @@
+package autotracker_test
+
+import (
+	"ivan/autotracker"
+	"os"
+	"testing"
+	"time"
+)
+
+const pollInterval = 100 // milliseconds
+
+func loadSaveContext(t *testing.T) []byte {
+	t.Helper()
+
+	mem, err := os.ReadFile("testdata/save_context.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mem
+}
+
+// poll starts an auto-tracker and returns the first item states it reads, or
+// false if none were read before the timeout.
+func poll(t *testing.T, config autotracker.Config, timeout time.Duration) (map[string]autotracker.ItemState, bool) {
+	t.Helper()
+
+	at := autotracker.New(config)
+	if at == nil {
+		t.Fatal("auto-tracker disabled")
+	}
+	defer at.Close()
+
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); {
+		if states, ok := at.Poll(); ok {
+			return states, true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return nil, false
+}
+
+// expectSaveContextStates checks the item states read from the save context
+// fixture.
+func expectSaveContextStates(t *testing.T, states map[string]autotracker.ItemState) {
+	t.Helper()
+
+	expected := map[string]autotracker.ItemState{
+		"Bow":                  {Enabled: true},
+		"Slingshot":            {},
+		"Fire Arrows":          {Enabled: true},
+		"Ice Arrows":           {},
+		"Ocarina":              {Enabled: true, UpgradeIndex: 1},
+		"Progressive Hookshot": {Enabled: true, UpgradeIndex: 1},
+		"Kokiri Sword":         {Enabled: true},
+		"Master Sword":         {},
+		"Hylian Shield":        {Enabled: true},
+		"Forest Medallion":     {Enabled: true},
+		"Zeldas Lullaby":       {Enabled: true},
+		"Kokiri Emerald":       {Enabled: true},
+		"Progressive Force":    {Enabled: true, UpgradeIndex: 1},
+		"Progressive Scale":    {},
+		"Wallet":               {Enabled: true, UpgradeIndex: 1},
+		"Magic Meter":          {Enabled: true},
+		"Mask Trade Sequence":  {Enabled: true, UpgradeIndex: 2},
+		"Bottle 1":             {Enabled: true},
+		"Bottle 2":             {Enabled: true},
+		"Bottle 3":             {},
+		"Rutos Letter":         {Enabled: true},
+		"Gold Skulltula Token": {Enabled: true, Count: 23},
+	}
+
+	for name, want := range expected {
+		if got, ok := states[name]; !ok || got != want {
+			t.Errorf("%s: got %+v, expected %+v", name, got, want)
+		}
+	}
+}
+
+func TestDecodeSaveContext(t *testing.T) {
+	states, ok := poll(t, retroArchConfig(t, loadSaveContext(t)), 2*time.Second)
+	if !ok {
+		t.Fatal("no item states polled")
+	}
+
+	expectSaveContextStates(t, states)
+}
+
+func TestDecodeSaveContextEmpty(t *testing.T) {
+	if _, ok := poll(t, retroArchConfig(t, make([]byte, len(loadSaveContext(t)))), 5*pollInterval*time.Millisecond); ok {
+		t.Error("expected no item states without a loaded save")
+	}
+}
@@ -0,0 +1,84 @@
+package autotracker
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const retroArchTimeout = 500 * time.Millisecond
+
+// retroArch reads memory through the RetroArch network command interface,
+// enabled with network_cmd_enable in retroarch.cfg.
+type retroArch struct {
+	conn net.Conn
+
+	saveContext uint32
+	wordSwap    bool
+}
+
+func newRetroArch(address string, saveContext uint32, wordSwap bool) (*retroArch, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to RetroArch: %w", err)
+	}
+
+	return &retroArch{conn: conn, saveContext: saveContext, wordSwap: wordSwap}, nil
+}
+
+func (ra *retroArch) ReadSaveContext() ([]byte, error) {
+	mem, err := ra.ReadMemory(ra.saveContext, saveContextSize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read save context: %w", err)
+	}
+
+	if ra.wordSwap {
+		swapWords(mem)
+	}
+
+	return mem, nil
+}
+
+func (ra *retroArch) Close() error {
+	return ra.conn.Close()
+}
+
+// ReadMemory sends READ_CORE_MEMORY and parses the "READ_CORE_MEMORY <addr>
+// <hex bytes...>" reply, errors being replied as a -1 length.
+func (ra *retroArch) ReadMemory(addr uint32, size int) ([]byte, error) {
+	if err := ra.conn.SetDeadline(time.Now().Add(retroArchTimeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := fmt.Fprintf(ra.conn, "READ_CORE_MEMORY %x %d\n", addr, size); err != nil {
+		return nil, fmt.Errorf("unable to send command: %w", err)
+	}
+
+	buf := make([]byte, 64+3*size)
+	n, err := ra.conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read reply: %w", err)
+	}
+
+	fields := strings.Fields(string(buf[:n]))
+	if len(fields) < 3 || fields[0] != "READ_CORE_MEMORY" {
+		return nil, fmt.Errorf("unexpected reply '%s'", buf[:n])
+	}
+
+	if fields[2] == "-1" {
+		return nil, fmt.Errorf("RetroArch error: %s", strings.Join(fields[3:], " "))
+	}
+
+	data, err := hex.DecodeString(strings.Join(fields[2:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid reply: %w", err)
+	}
+
+	if len(data) != size {
+		return nil, fmt.Errorf("read %d bytes, expected %d", len(data), size)
+	}
+
+	return data, nil
+}
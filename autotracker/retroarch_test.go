@@ -0,0 +1,99 @@
+package autotracker_test
+
+import (
+	"bytes"
+	"ivan/autotracker"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	fakeBase        = 0x8011A5D0
+	fakeSaveContext = "0x8011A5D0"
+)
+
+// listenFakeRetroArch answers READ_CORE_MEMORY commands from mem until the
+// test ends.
+func listenFakeRetroArch(t *testing.T, mem []byte) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			reply := autotracker.AnswerReadCoreMemory(strings.TrimSpace(string(buf[:n])), fakeBase, func() ([]byte, error) {
+				return mem, nil
+			})
+			_, _ = conn.WriteTo([]byte(reply+"\n"), from)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// retroArchConfig returns the config of an auto-tracker reading mem from a
+// fake RetroArch.
+func retroArchConfig(t *testing.T, mem []byte) autotracker.Config {
+	t.Helper()
+
+	return autotracker.Config{
+		Enabled:     true,
+		Protocol:    "RetroArch",
+		Address:     listenFakeRetroArch(t, mem),
+		SaveContext: fakeSaveContext,
+		Interval:    pollInterval,
+	}
+}
+
+// swapWords reverses the bytes of every 32-bit word, as stored by some
+// emulators.
+func swapWords(mem []byte) []byte {
+	ret := bytes.Clone(mem)
+	for k := 0; k+4 <= len(ret); k += 4 {
+		ret[k], ret[k+1], ret[k+2], ret[k+3] = ret[k+3], ret[k+2], ret[k+1], ret[k]
+	}
+
+	return ret
+}
+
+func TestRetroArchWordSwap(t *testing.T) {
+	config := retroArchConfig(t, swapWords(loadSaveContext(t)))
+	config.WordSwap = true
+
+	states, ok := poll(t, config, 2*time.Second)
+	if !ok {
+		t.Fatal("no item states polled")
+	}
+
+	expectSaveContextStates(t, states)
+}
+
+func TestRetroArchOutOfRange(t *testing.T) {
+	config := retroArchConfig(t, loadSaveContext(t))
+	config.SaveContext = "0x8011A5D4"
+
+	if _, ok := poll(t, config, 5*pollInterval*time.Millisecond); ok {
+		t.Error("expected no item states reading out of range")
+	}
+}
+
+func TestRetroArchInvalidAddress(t *testing.T) {
+	config := retroArchConfig(t, loadSaveContext(t))
+	config.SaveContext = "nowhere"
+
+	if autotracker.New(config) != nil {
+		t.Error("expected the auto-tracker to be disabled")
+	}
+}
@@ -0,0 +1,101 @@
+package autotracker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// OoT SRAM layout: three save slots followed by their backups.
+const (
+	sramSize       = 0x8000
+	sramSlotOffset = 0x20
+	sramSlotSize   = 0x1450
+	sramSlotCount  = 3
+
+	// Mupen64Plus .srm files hold the EEPROM, controller paks, SRAM, and
+	// FlashRAM of the game, in that order.
+	srmSize       = 0x48800
+	srmSRAMOffset = 0x20800
+)
+
+var saveMagic = []byte("ZELDAZ")
+
+// saveFile reads a save slot from an emulator save file, only when the file
+// changed.
+type saveFile struct {
+	path    string
+	slot    int
+	modTime time.Time
+	last    []byte
+}
+
+func newSaveFile(path string, slot int) (*saveFile, error) {
+	if path == "" {
+		return nil, errors.New("no save file set")
+	}
+
+	if slot < 1 || slot > sramSlotCount {
+		return nil, fmt.Errorf("invalid save slot %d, must be 1 to %d", slot, sramSlotCount)
+	}
+
+	return &saveFile{path: path, slot: slot}, nil
+}
+
+func (sf *saveFile) Close() error {
+	return nil
+}
+
+func (sf *saveFile) ReadSaveContext() ([]byte, error) {
+	info, err := os.Stat(sf.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read save file: %w", err)
+	}
+
+	if sf.last != nil && info.ModTime().Equal(sf.modTime) {
+		return sf.last, nil
+	}
+
+	data, err := os.ReadFile(sf.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read save file: %w", err)
+	}
+
+	mem, err := readSaveSlot(data, sf.slot)
+	if err != nil {
+		return nil, err
+	}
+
+	sf.modTime, sf.last = info.ModTime(), mem
+	return mem, nil
+}
+
+// readSaveSlot returns the big endian save context of a slot, or of its
+// backup if the slot is invalid. Both byte orders used by emulators are
+// accepted.
+func readSaveSlot(data []byte, slot int) ([]byte, error) {
+	sram := data
+	switch {
+	case len(data) == srmSize:
+		sram = data[srmSRAMOffset : srmSRAMOffset+sramSize]
+	case len(data) < sramSize:
+		return nil, fmt.Errorf("save file is too small (%d bytes)", len(data))
+	}
+
+	for _, index := range []int{slot - 1, slot - 1 + sramSlotCount} {
+		offset := sramSlotOffset + index*sramSlotSize
+		mem := bytes.Clone(sram[offset : offset+saveContextSize])
+		if bytes.Equal(mem[offsetNewf:offsetNewf+len(saveMagic)], saveMagic) {
+			return mem, nil
+		}
+
+		swapWords(mem)
+		if bytes.Equal(mem[offsetNewf:offsetNewf+len(saveMagic)], saveMagic) {
+			return mem, nil
+		}
+	}
+
+	return nil, fmt.Errorf("save slot %d is empty", slot)
+}
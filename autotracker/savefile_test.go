@@ -0,0 +1,96 @@
+package autotracker_test
+
+import (
+	"ivan/autotracker"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// OoT SRAM layout, and where Mupen64Plus .srm files hold it.
+const (
+	sramSize       = 0x8000
+	sramSlotOffset = 0x20
+	sramSlotSize   = 0x1450
+
+	srmSize       = 0x48800
+	srmSRAMOffset = 0x20800
+)
+
+// makeSRAM returns an SRAM image holding the save context in the given slot
+// index, backups being 3 to 5.
+func makeSRAM(mem []byte, index int, swap bool) []byte {
+	if swap {
+		mem = swapWords(mem)
+	}
+
+	sram := make([]byte, sramSize)
+	copy(sram[sramSlotOffset+index*sramSlotSize:], mem)
+
+	return sram
+}
+
+// saveFileConfig writes data to a save file and returns the config of an
+// auto-tracker reading the given slot from it.
+func saveFileConfig(t *testing.T, data []byte, slot int) autotracker.Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "save.sra")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return autotracker.Config{
+		Enabled:  true,
+		Protocol: "SaveFile",
+		SaveFile: path,
+		Slot:     slot,
+		Interval: pollInterval,
+	}
+}
+
+func TestReadSaveSlot(t *testing.T) {
+	mem := loadSaveContext(t)
+
+	srm := make([]byte, srmSize)
+	copy(srm[srmSRAMOffset:], makeSRAM(mem, 1, false))
+
+	tests := []struct {
+		name string
+		data []byte
+		slot int
+	}{
+		{"big endian", makeSRAM(mem, 0, false), 1},
+		{"word swapped", makeSRAM(mem, 2, true), 3},
+		{"backup", makeSRAM(mem, 4, false), 2},
+		{"srm", srm, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states, ok := poll(t, saveFileConfig(t, tt.data, tt.slot), 2*time.Second)
+			if !ok {
+				t.Fatal("no item states polled")
+			}
+
+			expectSaveContextStates(t, states)
+		})
+	}
+}
+
+func TestReadSaveSlotErrors(t *testing.T) {
+	mem := loadSaveContext(t)
+
+	if _, ok := poll(t, saveFileConfig(t, makeSRAM(mem, 0, false), 2), 5*pollInterval*time.Millisecond); ok {
+		t.Error("expected no item states reading an empty slot")
+	}
+
+	if _, ok := poll(t, saveFileConfig(t, make([]byte, 16), 1), 5*pollInterval*time.Millisecond); ok {
+		t.Error("expected no item states reading a truncated file")
+	}
+
+	if autotracker.New(saveFileConfig(t, makeSRAM(mem, 0, false), 4)) != nil {
+		t.Error("expected the auto-tracker to be disabled with an invalid slot")
+	}
+}
@@ -7,6 +7,7 @@ toolchain go1.24.2
 require (
 	github.com/bep/debounce v1.2.1
 	github.com/hajimehoshi/ebiten/v2 v2.8.7
+	github.com/jezek/xgb v1.1.1
 	github.com/lithammer/fuzzysearch v1.1.8
 	golang.org/x/image v0.26.0
 )
@@ -16,7 +17,6 @@ require (
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.8.2 // indirect
 	github.com/go-text/typesetting v0.2.0 // indirect
-	github.com/jezek/xgb v1.1.1 // indirect
 	golang.org/x/sync v0.13.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
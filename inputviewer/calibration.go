@@ -0,0 +1,234 @@
+package inputviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// calibrationThreshold is how far from its resting value an axis must move to
+// be detected during calibration.
+const calibrationThreshold = 0.5
+
+// calibration asks for every gamepad input in turn and writes the detected
+// IDs, directions, and dead zones to the configuration file.
+type calibration struct {
+	path   string // configuration file
+	config Config // configuration being calibrated
+
+	step        int       // index of the calibrated input
+	vertical    bool      // calibrating the vertical axis of a stick
+	rest        []float64 // raw axis values when calibration started
+	waitRelease bool      // wait for every input to be released
+}
+
+// ToggleCalibration starts calibrating the gamepad inputs, saving the result
+// to the given configuration file, or cancels the calibration.
+func (iv *InputViewer) ToggleCalibration(path string) {
+	if iv == nil {
+		return
+	}
+
+	if iv.calibration != nil {
+		log.Printf("info: calibration canceled")
+		iv.calibration = nil
+		return
+	}
+
+	if !iv.connected {
+		log.Printf("warning: no gamepad to calibrate")
+		return
+	}
+
+	config := iv.config
+	config.Inputs = append([]Input(nil), iv.config.Inputs...)
+
+	c := &calibration{
+		path:   path,
+		config: config,
+		rest:   make([]float64, ebiten.GamepadAxisCount(iv.id)),
+		step:   -1,
+
+		waitRelease: true,
+	}
+	for k := range c.rest {
+		c.rest[k] = ebiten.GamepadAxisValue(iv.id, k)
+	}
+
+	if !c.next() {
+		log.Printf("warning: no gamepad input to calibrate")
+		return
+	}
+
+	iv.calibration = c
+	log.Printf("info: calibrating %s", ebiten.GamepadName(iv.id))
+}
+
+// next moves to the next gamepad input, returning false when done.
+func (c *calibration) next() bool {
+	if c.step >= 0 && c.config.Inputs[c.step].Type == inputTypeStick && !c.vertical {
+		c.vertical = true
+		return true
+	}
+
+	c.vertical = false
+	for c.step++; c.step < len(c.config.Inputs); c.step++ {
+		if !c.config.Inputs[c.step].keyDriven() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *calibration) prompt() string {
+	in := c.config.Inputs[c.step]
+	if in.Type != inputTypeStick {
+		return fmt.Sprintf("press %s (F6 cancels)", in.Name)
+	}
+
+	if c.vertical {
+		return fmt.Sprintf("push %s down", in.Name)
+	}
+
+	return fmt.Sprintf("push %s right", in.Name)
+}
+
+// movedAxis returns the raw axis that moved the most past the calibration
+// threshold, if any.
+func (c *calibration) movedAxis(id ebiten.GamepadID) (axis int, delta float64, ok bool) {
+	for k, rest := range c.rest {
+		v := ebiten.GamepadAxisValue(id, k) - rest
+		if math.Abs(v) > calibrationThreshold && math.Abs(v) > math.Abs(delta) {
+			axis, delta, ok = k, v, true
+		}
+	}
+
+	return axis, delta, ok
+}
+
+func (c *calibration) released(id ebiten.GamepadID) bool {
+	if len(inpututil.AppendPressedGamepadButtons(id, nil)) > 0 {
+		return false
+	}
+
+	_, _, moved := c.movedAxis(id)
+	return !moved
+}
+
+// calibrate detects the input of the current step, once per update.
+func (iv *InputViewer) calibrate() {
+	c := iv.calibration
+	if !iv.connected {
+		return
+	}
+
+	if c.waitRelease {
+		c.waitRelease = !c.released(iv.id)
+		return
+	}
+
+	in := &c.config.Inputs[c.step]
+	var detected bool
+	if in.Type == inputTypeStick {
+		detected = c.detectStick(iv.id, in)
+	} else {
+		detected = c.detectButton(iv.id, in)
+	}
+
+	if !detected {
+		return
+	}
+
+	c.waitRelease = true
+	if !c.next() {
+		iv.finishCalibration()
+	}
+}
+
+func (c *calibration) detectButton(id ebiten.GamepadID, in *Input) bool {
+	if buttons := inpututil.AppendJustPressedGamepadButtons(id, nil); len(buttons) > 0 {
+		in.Type, in.ID, in.Dir, in.DeadZone = inputTypeButton, int(buttons[0]), 0, 0
+	} else if axis, delta, ok := c.movedAxis(id); ok {
+		in.Type, in.ID, in.Dir = inputTypeAxis, axis, 1
+		if delta < 0 {
+			in.Dir = -1
+		}
+
+		// Halfway between the resting value and the detection threshold.
+		in.DeadZone = max(axisDeadZone, c.rest[axis]*float64(in.Dir)+calibrationThreshold/2)
+	} else {
+		return false
+	}
+
+	// Standard names are only kept for the same kind of input, the IDs are
+	// used when the standard layout is unavailable.
+	in.Standard = ""
+	if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+		return true
+	}
+
+	if in.Type == inputTypeButton {
+		if buttons := inpututil.AppendJustPressedStandardGamepadButtons(id, nil); len(buttons) > 0 {
+			in.Standard = standardButtonName(buttons[0])
+		}
+		return true
+	}
+
+	for name, axis := range standardAxes {
+		if axisPressed(ebiten.StandardGamepadAxisValue(id, axis), in.Dir, calibrationThreshold) {
+			in.Standard = name
+			break
+		}
+	}
+
+	return true
+}
+
+func (c *calibration) detectStick(id ebiten.GamepadID, in *Input) bool {
+	axis, _, ok := c.movedAxis(id)
+	if !ok {
+		return false
+	}
+
+	if c.vertical {
+		in.IDY = axis
+		return true
+	}
+
+	in.IDX, in.Standard = axis, ""
+	if ebiten.IsStandardGamepadLayoutAvailable(id) {
+		for name, axes := range standardSticks {
+			if ebiten.StandardGamepadAxisValue(id, axes[0]) > calibrationThreshold {
+				in.Standard = name
+			}
+		}
+	}
+
+	return true
+}
+
+// finishCalibration applies the calibrated configuration and writes it.
+func (iv *InputViewer) finishCalibration() {
+	c := iv.calibration
+	iv.calibration = nil
+	iv.config = c.config
+
+	data, err := json.MarshalIndent(c.config, "", "  ")
+	if err != nil {
+		log.Printf("error: unable to encode calibration: %s", err)
+		return
+	}
+
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		log.Printf("error: unable to write calibration: %s", err)
+		return
+	}
+
+	log.Printf("info: calibration saved to %s", c.path)
+}
@@ -0,0 +1,203 @@
+package inputviewer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	defaultHistoryDuration = 5 // seconds
+	historyLabelWidth      = 60
+	historyRowHeight       = 16
+)
+
+type historyConfig struct {
+	Duration float64 // seconds of input shown in the history panel
+	Trail    int     // number of stick positions drawn behind the marker
+}
+
+type historySample struct {
+	at      time.Time
+	pressed []bool // by input index
+}
+
+// history holds the recent input state, press counts, and stick positions.
+type history struct {
+	bounds   image.Rectangle // empty if the panel is hidden
+	duration time.Duration
+
+	samples []historySample
+	counts  []int          // by input index
+	trails  [][][2]float64 // stick positions by input index, oldest first
+}
+
+func newHistory(config historyConfig, bounds image.Rectangle, inputs int) history {
+	duration := config.Duration
+	if duration <= 0 {
+		duration = defaultHistoryDuration
+	}
+
+	return history{
+		bounds:   bounds,
+		duration: time.Duration(duration * float64(time.Second)),
+		counts:   make([]int, inputs),
+		trails:   make([][][2]float64, inputs),
+	}
+}
+
+// ResetHistory clears the history and press counters.
+func (iv *InputViewer) ResetHistory() {
+	if iv == nil {
+		return
+	}
+
+	iv.history = newHistory(iv.config.History, iv.history.bounds, len(iv.config.Inputs))
+}
+
+// sample records the state of every input, once per update.
+func (iv *InputViewer) sample(now time.Time) {
+	var (
+		h       = &iv.history
+		pressed = make([]bool, len(iv.config.Inputs))
+		last    []bool
+	)
+	if len(h.samples) > 0 {
+		last = h.samples[len(h.samples)-1].pressed
+	}
+
+	for k, v := range iv.config.Inputs {
+		pressed[k] = iv.state.pressed[k]
+		if !iv.state.available[k] {
+			continue
+		}
+
+		if v.Type != inputTypeStick {
+			if pressed[k] && (last == nil || !last[k]) {
+				h.counts[k]++
+			}
+			continue
+		}
+
+		if iv.config.History.Trail > 0 {
+			h.trails[k] = append(h.trails[k], iv.state.axes[k])
+			if len(h.trails[k]) > iv.config.History.Trail {
+				h.trails[k] = h.trails[k][1:]
+			}
+		}
+	}
+
+	h.samples = append(h.samples, historySample{at: now, pressed: pressed})
+
+	// Keep one sample past the duration for presses starting out of the panel.
+	var expired int
+	for expired < len(h.samples)-1 && now.Sub(h.samples[expired+1].at) > h.duration {
+		expired++
+	}
+	h.samples = h.samples[expired:]
+}
+
+// drawHistory draws a row for every input besides sticks with its press count
+// and a timeline of its presses, the latest on the right.
+func (iv *InputViewer) drawHistory(screen *ebiten.Image) {
+	h := iv.history
+	if h.bounds.Empty() {
+		return
+	}
+
+	vector.DrawFilledRect(
+		screen,
+		float32(h.bounds.Min.X), float32(h.bounds.Min.Y),
+		float32(h.bounds.Dx()), float32(h.bounds.Dy()),
+		iv.theme.PanelBackground,
+		false,
+	)
+
+	if len(h.samples) == 0 {
+		return
+	}
+
+	var (
+		now   = h.samples[len(h.samples)-1].at
+		left  = h.bounds.Min.X + historyLabelWidth
+		width = h.bounds.Max.X - left
+		y     = h.bounds.Min.Y
+	)
+
+	timeToX := func(at time.Time) float32 {
+		ratio := float64(now.Sub(at)) / float64(h.duration)
+		return float32(h.bounds.Max.X) - float32(min(1, ratio)*float64(width))
+	}
+
+	for k, v := range iv.config.Inputs {
+		if v.Type == inputTypeStick {
+			continue
+		}
+		if y+historyRowHeight > h.bounds.Max.Y {
+			break
+		}
+
+		iv.drawText(screen, fmt.Sprintf("%s %d", v.Name, h.counts[k]), h.bounds.Min.X+2, y)
+
+		clr := iv.inputColor(v)
+
+		var start *time.Time
+		for i, sample := range h.samples {
+			if sample.pressed[k] && start == nil {
+				start = &h.samples[i].at
+			}
+
+			if start != nil && (!sample.pressed[k] || i == len(h.samples)-1) {
+				x0, x1 := timeToX(*start), timeToX(sample.at)
+				vector.DrawFilledRect(
+					screen,
+					x0, float32(y+4),
+					max(1, x1-x0), historyRowHeight-8,
+					clr,
+					false,
+				)
+				start = nil
+			}
+		}
+
+		y += historyRowHeight
+	}
+}
+
+// inputColor returns the color of an input in the history panel and stick
+// trails, the theme text color if the input has none.
+func (iv *InputViewer) inputColor(in Input) color.RGBA {
+	if in.Color == (color.RGBA{}) {
+		return color.RGBA(iv.theme.Text)
+	}
+
+	return in.color()
+}
+
+// drawStickTrail draws the previous positions of a stick marker, fading out.
+func (iv *InputViewer) drawStickTrail(screen *ebiten.Image, k int, in Input, travel float64) {
+	trail := iv.history.trails[k]
+	for i, v := range trail {
+		alpha := float32(i+1) / float32(len(trail)+1)
+		clr := iv.inputColor(in)
+		clr = color.RGBA{
+			uint8(float32(clr.R) * alpha),
+			uint8(float32(clr.G) * alpha),
+			uint8(float32(clr.B) * alpha),
+			uint8(0xFF * alpha),
+		}
+
+		vector.DrawFilledRect(
+			screen,
+			float32(in.Pos.X)+float32(travel*v[0])-1,
+			float32(in.Pos.Y)+float32(travel*v[1])-1,
+			2, 2,
+			clr,
+			false,
+		)
+	}
+}
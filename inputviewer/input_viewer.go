@@ -3,211 +3,489 @@ package inputviewer
 import (
 	"image"
 	"image/color"
+	"ivan/theme"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 const (
 	btnSpriteWidth  = 34
 	btnSpriteHeight = btnSpriteWidth
+
+	defaultSheetPressed  = "assets/items.png"
+	defaultSheetReleased = "assets/items-disabled.png"
+
+	defaultStickRange = 14
+	axisDeadZone      = 0.15
 )
 
 type Config struct {
-	Enabled                   bool
-	A, B, Z, R, Start         InputButton
-	CUp, CRight, CDown, CLeft InputButton
+	Enabled bool
 
-	J InputJoystick
-}
+	// Gamepad to read input from, by SDL GUID or name, the first connected
+	// gamepad is used when empty.
+	Gamepad string
 
-type InputButton struct {
-	Type string // (Button, Axis)
-	ID   int
-	Dir  int // -1 / 1
+	// Read Key inputs from the X server instead of the Ivan window, to see
+	// them while the emulator is focused. Linux/X11 only.
+	GlobalKeys bool
 
-	Pos, SheetPos image.Point
+	// Input history panel, drawn in the InputHistory layout rectangle, and
+	// stick trails.
+	History historyConfig
 
-	Color color.RGBA
+	Inputs []Input // drawn in order
 }
 
-// Rect returns the position of the button gfx relative to the background origin.
-func (ib InputButton) Rect() image.Rectangle {
-	dX, dY := btnSpriteWidth/2, btnSpriteHeight/2
+type inputType string
 
-	return image.Rect(
-		ib.Pos.X-dX, ib.Pos.Y-dX,
-		ib.Pos.X+btnSpriteWidth-dX, ib.Pos.Y+btnSpriteHeight-dY,
-	)
+const (
+	inputTypeButton inputType = "Button"
+	inputTypeAxis   inputType = "Axis"
+	inputTypeStick  inputType = "Stick"
+	inputTypeKey    inputType = "Key"
+)
+
+type inputShape string
+
+const (
+	inputShapeSprite inputShape = "Sprite" // default
+	inputShapeRect   inputShape = "Rect"
+	inputShapeCircle inputShape = "Circle"
+)
+
+// Input is a button, an axis used as a button, or a stick of the gamepad, or
+// a keyboard key.
+type Input struct {
+	Name string
+	Type inputType
+
+	ID       int        // button or axis
+	Dir      int        `json:",omitempty"` // -1 / 1, for axes
+	IDX, IDY int        `json:",omitempty"` // for sticks
+	Key      ebiten.Key `json:",omitempty"` // for keys, by name, eg. "ArrowUp"
+
+	// Up, down, left, and right keys driving a stick instead of its axes.
+	Keys []ebiten.Key `json:",omitempty"`
+
+	// Name of the button, axis, or stick in the standard gamepad layout, used
+	// instead of the IDs when the gamepad has a standard mapping.
+	Standard string `json:",omitempty"`
+
+	// Value past which a button or axis is pressed, defaults to 0.15.
+	DeadZone float64 `json:",omitempty"`
+
+	Pos  image.Point // center of the input on screen
+	Size image.Point // sprite or shape size, defaults to the item sprite size
+
+	// Sprite position on the sheets, and optional sheets replacing the items
+	// spritesheets for pressed and released inputs.
+	SheetPos                    image.Point
+	SheetPressed, SheetReleased string `json:",omitempty"`
+
+	// Sprites are drawn from the pressed sheet when pressed and the released
+	// one otherwise, other shapes are filled when pressed and outlined
+	// otherwise. HideReleased skips drawing released inputs.
+	Shape        inputShape `json:",omitempty"`
+	HideReleased bool       `json:",omitempty"`
+	Color        color.RGBA
+
+	// Travel of the stick position marker, in pixels.
+	Range float64 `json:",omitempty"`
 }
 
-// SheetRect returns the position of the button gfx sprite on the spritesheet.
-func (ib InputButton) SheetRect() image.Rectangle {
-	return image.Rect(
-		ib.SheetPos.X, ib.SheetPos.Y,
-		ib.SheetPos.X+btnSpriteWidth, ib.SheetPos.Y+btnSpriteHeight,
-	)
+func (in Input) deadZone() float64 {
+	if in.DeadZone == 0 {
+		return axisDeadZone
+	}
+
+	return in.DeadZone
 }
 
-type InputJoystick struct {
-	IDX, IDY int
+// axisPressed returns true if the axis is pushed past the dead zone in the
+// given direction, positive if unset.
+func axisPressed(value float64, dir int, deadZone float64) bool {
+	if dir < 0 {
+		return value < -deadZone
+	}
 
-	Pos, SheetPos image.Point
-	Color         color.RGBA
+	return value > deadZone
 }
 
-// Rect returns the position of the joystick gfx relative to the background origin.
-func (ij InputJoystick) Rect() image.Rectangle {
-	dX, dY := btnSpriteWidth/2, btnSpriteHeight/2
+func (in Input) size() image.Point {
+	if in.Size == (image.Point{}) {
+		return image.Point{btnSpriteWidth, btnSpriteHeight}
+	}
 
-	return image.Rect(
-		ij.Pos.X-dX, ij.Pos.Y-dX,
-		ij.Pos.X+btnSpriteWidth-dX, ij.Pos.Y+btnSpriteHeight-dY,
-	)
+	return in.Size
 }
 
-// SheetRect returns the position of the joystick gfx sprite on the spritesheet.
-func (ij InputJoystick) SheetRect() image.Rectangle {
-	return image.Rect(
-		ij.SheetPos.X, ij.SheetPos.Y,
-		ij.SheetPos.X+btnSpriteWidth, ij.SheetPos.Y+btnSpriteHeight,
-	)
+// Rect returns the position of the input gfx relative to the background origin.
+func (in Input) Rect() image.Rectangle {
+	size := in.size()
+	min := in.Pos.Sub(size.Div(2))
+
+	return image.Rectangle{min, min.Add(size)}
 }
 
-func (ij InputJoystick) axes(id ebiten.GamepadID) (float64, float64) {
-	return ebiten.GamepadAxisValue(id, ij.IDX), ebiten.GamepadAxisValue(id, ij.IDY)
+// SheetRect returns the position of the input gfx sprite on the spritesheet.
+func (in Input) SheetRect() image.Rectangle {
+	return image.Rectangle{in.SheetPos, in.SheetPos.Add(in.size())}
 }
 
-func (ib InputButton) pressed(id ebiten.GamepadID) bool {
-	switch ib.Type {
-	case "Button":
-		return ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton(ib.ID))
-	case "Axis":
-		if ib.Dir < 0 {
-			return ebiten.GamepadAxisValue(id, ib.ID) < -0.15
-		}
-		return ebiten.GamepadAxisValue(id, ib.ID) > 0.15
-	}
+func (in Input) color() color.RGBA {
+	return color.RGBA{in.Color.R, in.Color.G, in.Color.B, 0xFF}
+}
 
-	return false
+// keyDriven returns true if the input is read from the keyboard.
+func (in Input) keyDriven() bool {
+	return in.Type == inputTypeKey || (in.Type == inputTypeStick && len(in.Keys) == 4)
 }
 
 type InputViewer struct {
 	config Config
-	id     ebiten.GamepadID
 
-	sheetEnabled, sheetDisabled *ebiten.Image
+	id        ebiten.GamepadID
+	connected bool
+	wanted    string // SDL GUID or name of the gamepad to reconnect to
+
+	keys    keySource
+	history history
+
+	state     inputState
+	recorder  *recorder // nil when not recording
+	replaying bool
+
+	calibration *calibration // nil when not calibrating
+
+	theme *theme.Theme
+
+	sheets map[string]*ebiten.Image // by path
 }
 
-func NewInputViewer(config Config) *InputViewer {
+// NewInputViewer returns an input viewer drawing its history panel in the
+// given bounds, if not empty.
+func NewInputViewer(config Config, historyBounds image.Rectangle, th *theme.Theme) *InputViewer {
 	if !config.Enabled {
 		return nil
 	}
 
-	ids := ebiten.AppendGamepadIDs(nil)
-	if len(ids) == 0 {
-		return nil
+	iv := &InputViewer{
+		config:  config,
+		wanted:  config.Gamepad,
+		keys:    focusedKeys{},
+		history: newHistory(config.History, historyBounds, len(config.Inputs)),
+		state:   newInputState(len(config.Inputs)),
+		sheets:  make(map[string]*ebiten.Image),
+		theme:   th,
 	}
-	id := ids[0]
-
-	log.Printf("info: reading input from %s", ebiten.GamepadName(id))
 
-	iv := &InputViewer{
-		config: config,
-		id:     id,
+	if config.GlobalKeys {
+		keys, err := newGlobalKeySource()
+		if err != nil {
+			log.Printf("warning: unable to read global keys, reading keys from the Ivan window: %s", err)
+		} else {
+			iv.keys = keys
+		}
 	}
 
-	images := []struct {
-		img  **ebiten.Image
-		path string
-	}{
-		// HACK resources are loaded twice
-		{&iv.sheetDisabled, "assets/items-disabled.png"},
-		{&iv.sheetEnabled, "assets/items.png"},
+	// HACK default resources are loaded twice
+	paths := []string{defaultSheetPressed, defaultSheetReleased}
+	for _, v := range config.Inputs {
+		paths = append(paths, v.SheetPressed, v.SheetReleased)
 	}
 
-	var err error
-	for _, v := range images {
-		*v.img, _, err = ebitenutil.NewImageFromFile(v.path)
+	for _, path := range paths {
+		if _, ok := iv.sheets[path]; ok || path == "" {
+			continue
+		}
+
+		img, _, err := ebitenutil.NewImageFromFile(path)
 		if err != nil {
 			log.Fatal(err)
 		}
+		iv.sheets[path] = img
 	}
 
 	return iv
 }
 
+// matches returns true if the gamepad is the one we want to read input from.
+func (iv *InputViewer) matches(id ebiten.GamepadID) bool {
+	if iv.wanted == "" {
+		return true
+	}
+
+	return ebiten.GamepadSDLID(id) == iv.wanted ||
+		strings.Contains(strings.ToLower(ebiten.GamepadName(id)), strings.ToLower(iv.wanted))
+}
+
+// Update polls the keyboard and gamepad and records the input history.
+func (iv *InputViewer) Update() {
+	if iv == nil {
+		return
+	}
+
+	iv.keys.update()
+	iv.updateGamepad()
+	if iv.calibration != nil {
+		iv.calibrate()
+	}
+	iv.readState()
+	if iv.recorder != nil {
+		iv.record()
+	}
+	iv.sample(time.Now())
+}
+
+func (iv *InputViewer) readState() {
+	for k, v := range iv.config.Inputs {
+		iv.state.available[k] = iv.available(v)
+		iv.state.pressed[k] = iv.state.available[k] && iv.pressed(v)
+		if v.Type == inputTypeStick && iv.state.available[k] {
+			x, y := iv.axes(v)
+			iv.state.axes[k] = [2]float64{x, y}
+		}
+	}
+}
+
+// updateGamepad detects the gamepad being disconnected and connects to the
+// wanted gamepad once it is available.
+func (iv *InputViewer) updateGamepad() {
+	ids := ebiten.AppendGamepadIDs(nil)
+	if iv.connected && slices.Contains(ids, iv.id) {
+		return
+	}
+
+	if iv.connected {
+		log.Printf("warning: gamepad disconnected")
+		iv.connected = false
+	}
+
+	for _, id := range ids {
+		if iv.matches(id) {
+			iv.connect(id)
+			return
+		}
+	}
+}
+
+// NextGamepad reads input from the next connected gamepad, it becomes the
+// gamepad to reconnect to.
+func (iv *InputViewer) NextGamepad() {
+	if iv == nil {
+		return
+	}
+
+	ids := ebiten.AppendGamepadIDs(nil)
+	if len(ids) == 0 {
+		return
+	}
+	slices.Sort(ids)
+
+	next := ids[0]
+	if iv.connected {
+		if k := slices.Index(ids, iv.id); k >= 0 {
+			next = ids[(k+1)%len(ids)]
+		}
+	}
+
+	iv.wanted = ebiten.GamepadSDLID(next)
+	iv.connect(next)
+}
+
+func (iv *InputViewer) connect(id ebiten.GamepadID) {
+	iv.id, iv.connected = id, true
+	log.Printf("info: reading input from %s (%s)", ebiten.GamepadName(id), ebiten.GamepadSDLID(id))
+}
+
+func (iv *InputViewer) axes(in Input) (float64, float64) {
+	if !in.keyDriven() {
+		if x, y, ok := iv.standardStick(in); ok {
+			return x, y
+		}
+
+		return ebiten.GamepadAxisValue(iv.id, in.IDX), ebiten.GamepadAxisValue(iv.id, in.IDY)
+	}
+
+	var x, y float64
+	if iv.keys.isPressed(in.Keys[0]) {
+		y--
+	}
+	if iv.keys.isPressed(in.Keys[1]) {
+		y++
+	}
+	if iv.keys.isPressed(in.Keys[2]) {
+		x--
+	}
+	if iv.keys.isPressed(in.Keys[3]) {
+		x++
+	}
+
+	return x, y
+}
+
+func (iv *InputViewer) pressed(in Input) bool {
+	if pressed, ok := iv.standardPressed(in); ok {
+		return pressed
+	}
+
+	switch in.Type {
+	case inputTypeKey:
+		return iv.keys.isPressed(in.Key)
+	case inputTypeButton:
+		return ebiten.IsGamepadButtonPressed(iv.id, ebiten.GamepadButton(in.ID))
+	case inputTypeAxis:
+		return axisPressed(ebiten.GamepadAxisValue(iv.id, in.ID), in.Dir, in.deadZone())
+	case inputTypeStick:
+		x, y := iv.axes(in)
+		return x*x+y*y > in.deadZone()*in.deadZone()
+	}
+
+	return false
+}
+
+// available returns true if the input can be read.
+func (iv *InputViewer) available(in Input) bool {
+	return iv.connected || in.keyDriven()
+}
+
+func (iv *InputViewer) sheet(in Input, pressed bool) *ebiten.Image {
+	if pressed {
+		if in.SheetPressed != "" {
+			return iv.sheets[in.SheetPressed]
+		}
+		return iv.sheets[defaultSheetPressed]
+	}
+
+	if in.SheetReleased != "" {
+		return iv.sheets[in.SheetReleased]
+	}
+	return iv.sheets[defaultSheetReleased]
+}
+
 func (iv *InputViewer) Draw(screen *ebiten.Image) {
 	if iv == nil { // allow ignoring input viewer
 		return
 	}
 
-	iv.legacyDraw(screen)
-	iv.drawButtons(screen)
-	iv.drawJoystick(screen)
+	var (
+		bounds       image.Rectangle
+		needsGamepad bool
+	)
+	iv.drawHistory(screen)
+
+	for k, v := range iv.config.Inputs {
+		iv.drawInput(screen, k, v)
+		bounds = bounds.Union(v.Rect())
+		needsGamepad = needsGamepad || !v.keyDriven()
+	}
+
+	var status string
+	switch {
+	case iv.calibration != nil:
+		status = iv.calibration.prompt()
+	case iv.recorder != nil:
+		status = "REC"
+	case needsGamepad && !iv.connected && !iv.replaying:
+		status = "no gamepad"
+	}
+
+	if status != "" {
+		iv.drawText(screen, status, bounds.Min.X, bounds.Max.Y)
+	}
 }
 
-func (iv *InputViewer) drawJoystick(screen *ebiten.Image) {
-	op := ebiten.DrawImageOptions{}
+// SetTheme changes the font and text color of the input viewer.
+func (iv *InputViewer) SetTheme(th *theme.Theme) {
+	if iv == nil {
+		return
+	}
 
-	j := iv.config.J
-	op.GeoM.Reset()
-	op.GeoM.Translate(float64(j.Rect().Min.X), float64(j.Rect().Min.Y))
-	screen.DrawImage(
-		iv.sheetEnabled.SubImage(j.SheetRect()).(*ebiten.Image),
-		&op,
-	)
-	x, y := j.axes(iv.id)
-	vector.DrawFilledRect(
-		screen,
-		float32(j.Pos.X)+14.0*float32(x)-1,
-		float32(j.Pos.Y)+14.0*float32(y)-1,
-		2, 2,
-		color.RGBA{j.Color.R, j.Color.G, j.Color.B, 0xFF},
-		false,
-	)
+	iv.theme = th
 }
 
-func (iv *InputViewer) drawButtons(screen *ebiten.Image) {
-	op := ebiten.DrawImageOptions{}
+// drawText draws a line of text with its top left corner at x, y.
+func (iv *InputViewer) drawText(screen *ebiten.Image, str string, x, y int) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(iv.theme.Text)
+	text.Draw(screen, str, iv.theme.SmallFace(), op)
+}
 
-	for _, v := range []*InputButton{
-		&iv.config.A, &iv.config.B,
-		&iv.config.Start,
-		&iv.config.CUp, &iv.config.CRight,
-		&iv.config.CDown, &iv.config.CLeft,
-	} {
-		sheet := iv.sheetEnabled
-		if !v.pressed(iv.id) {
-			sheet = iv.sheetDisabled
-		}
+// drawInput draws gamepad inputs as released when no gamepad is connected.
+func (iv *InputViewer) drawInput(screen *ebiten.Image, k int, in Input) {
+	available := iv.state.available[k]
+	pressed := iv.state.pressed[k]
+	if in.Type == inputTypeStick {
+		pressed = available // the marker shows the input
+	}
 
-		op.GeoM.Reset()
-		op.GeoM.Translate(float64(v.Rect().Min.X), float64(v.Rect().Min.Y))
+	if !pressed && in.HideReleased {
+		return
+	}
+
+	rect := in.Rect()
+	switch in.Shape {
+	case inputShapeRect:
+		if pressed {
+			vector.DrawFilledRect(
+				screen,
+				float32(rect.Min.X), float32(rect.Min.Y),
+				float32(rect.Dx()), float32(rect.Dy()),
+				in.color(), false,
+			)
+		} else {
+			vector.StrokeRect(
+				screen,
+				float32(rect.Min.X), float32(rect.Min.Y),
+				float32(rect.Dx()), float32(rect.Dy()),
+				1, in.color(), false,
+			)
+		}
+	case inputShapeCircle:
+		radius := float32(min(rect.Dx(), rect.Dy())) / 2
+		if pressed {
+			vector.DrawFilledCircle(screen, float32(in.Pos.X), float32(in.Pos.Y), radius, in.color(), true)
+		} else {
+			vector.StrokeCircle(screen, float32(in.Pos.X), float32(in.Pos.Y), radius, 1, in.color(), true)
+		}
+	case inputShapeSprite, "":
+		op := ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(rect.Min.X), float64(rect.Min.Y))
 		screen.DrawImage(
-			sheet.SubImage(v.SheetRect()).(*ebiten.Image),
+			iv.sheet(in, pressed).SubImage(in.SheetRect()).(*ebiten.Image),
 			&op,
 		)
 	}
-}
 
-// TODO replace when a better idea is found for Z/R.
-func (iv *InputViewer) legacyDraw(screen *ebiten.Image) {
-	for _, v := range []*InputButton{
-		&iv.config.Z, &iv.config.R,
-	} {
-		if !v.pressed(iv.id) {
-			continue
-		}
+	if in.Type == inputTypeStick && available {
+		iv.drawStickMarker(screen, k, in)
+	}
+}
 
-		vector.DrawFilledRect(
-			screen,
-			float32(v.Pos.X), float32(v.Pos.Y),
-			2, 2,
-			color.RGBA{v.Color.R, v.Color.G, v.Color.B, 0xFF},
-			false,
-		)
+func (iv *InputViewer) drawStickMarker(screen *ebiten.Image, k int, in Input) {
+	travel := in.Range
+	if travel == 0 {
+		travel = defaultStickRange
 	}
+
+	iv.drawStickTrail(screen, k, in, travel)
+
+	x, y := iv.state.axes[k][0], iv.state.axes[k][1]
+	vector.DrawFilledRect(
+		screen,
+		float32(in.Pos.X)+float32(travel*x)-1,
+		float32(in.Pos.Y)+float32(travel*y)-1,
+		2, 2,
+		in.color(),
+		false,
+	)
 }
@@ -0,0 +1,18 @@
+package inputviewer
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// keySource reads the keyboard state for Key inputs and key driven sticks.
+type keySource interface {
+	update()
+	isPressed(key ebiten.Key) bool
+}
+
+// focusedKeys reads the keyboard through ebiten, only while Ivan is focused.
+type focusedKeys struct{}
+
+func (focusedKeys) update() {}
+
+func (focusedKeys) isPressed(key ebiten.Key) bool {
+	return ebiten.IsKeyPressed(key)
+}
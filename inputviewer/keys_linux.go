@@ -0,0 +1,114 @@
+package inputviewer
+
+import (
+	"ivan/inputviewer/x11"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// globalKeys reads the keyboard from the X server, regardless of the focused
+// window.
+type globalKeys struct {
+	conn   *x11.Conn
+	keymap x11.Keymap
+	failed bool
+}
+
+func newGlobalKeySource() (keySource, error) {
+	conn, err := x11.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return &globalKeys{conn: conn}, nil
+}
+
+func (keys *globalKeys) update() {
+	if keys.failed {
+		return
+	}
+
+	keymap, err := keys.conn.QueryKeymap()
+	if err != nil {
+		log.Printf("error: global keys disabled: %s", err)
+		keys.failed = true
+		keys.keymap = x11.Keymap{}
+		return
+	}
+
+	keys.keymap = keymap
+}
+
+func (keys *globalKeys) isPressed(key ebiten.Key) bool {
+	for _, keysym := range keysyms[key] {
+		if keys.conn.IsPressed(keys.keymap, keysym) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keysyms maps ebiten keys to the X11 keysyms they produce.
+var keysyms = func() map[ebiten.Key][]uint32 {
+	ret := map[ebiten.Key][]uint32{
+		ebiten.KeyArrowLeft:  {0xFF51},
+		ebiten.KeyArrowUp:    {0xFF52},
+		ebiten.KeyArrowRight: {0xFF53},
+		ebiten.KeyArrowDown:  {0xFF54},
+
+		ebiten.KeySpace:        {0x20},
+		ebiten.KeyEnter:        {0xFF0D},
+		ebiten.KeyEscape:       {0xFF1B},
+		ebiten.KeyTab:          {0xFF09},
+		ebiten.KeyBackspace:    {0xFF08},
+		ebiten.KeyInsert:       {0xFF63},
+		ebiten.KeyDelete:       {0xFFFF},
+		ebiten.KeyHome:         {0xFF50},
+		ebiten.KeyEnd:          {0xFF57},
+		ebiten.KeyPageUp:       {0xFF55},
+		ebiten.KeyPageDown:     {0xFF56},
+		ebiten.KeyShiftLeft:    {0xFFE1},
+		ebiten.KeyShiftRight:   {0xFFE2},
+		ebiten.KeyControlLeft:  {0xFFE3},
+		ebiten.KeyControlRight: {0xFFE4},
+		ebiten.KeyAltLeft:      {0xFFE9},
+		ebiten.KeyAltRight:     {0xFFEA, 0xFE03}, // AltGr
+		ebiten.KeyCapsLock:     {0xFFE5},
+
+		ebiten.KeyComma:        {0x2C},
+		ebiten.KeyPeriod:       {0x2E},
+		ebiten.KeySlash:        {0x2F},
+		ebiten.KeySemicolon:    {0x3B},
+		ebiten.KeyQuote:        {0x27},
+		ebiten.KeyBracketLeft:  {0x5B},
+		ebiten.KeyBracketRight: {0x5D},
+		ebiten.KeyBackslash:    {0x5C},
+		ebiten.KeyMinus:        {0x2D},
+		ebiten.KeyEqual:        {0x3D},
+		ebiten.KeyBackquote:    {0x60},
+
+		ebiten.KeyNumpadEnter:    {0xFF8D},
+		ebiten.KeyNumpadAdd:      {0xFFAB},
+		ebiten.KeyNumpadSubtract: {0xFFAD},
+		ebiten.KeyNumpadMultiply: {0xFFAA},
+		ebiten.KeyNumpadDivide:   {0xFFAF},
+		ebiten.KeyNumpadDecimal:  {0xFFAE, 0xFF9F}, // KP_Delete
+	}
+
+	for k := range 26 {
+		ret[ebiten.KeyA+ebiten.Key(k)] = []uint32{uint32('a' + k), uint32('A' + k)}
+	}
+
+	for k := range 10 {
+		ret[ebiten.KeyDigit0+ebiten.Key(k)] = []uint32{uint32('0' + k)}
+		ret[ebiten.KeyNumpad0+ebiten.Key(k)] = []uint32{uint32(0xFFB0 + k)}
+	}
+
+	for k := range 12 {
+		ret[ebiten.KeyF1+ebiten.Key(k)] = []uint32{uint32(0xFFBE + k)}
+	}
+
+	return ret
+}()
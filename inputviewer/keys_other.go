@@ -0,0 +1,9 @@
+//go:build !linux
+
+package inputviewer
+
+import "errors"
+
+func newGlobalKeySource() (keySource, error) {
+	return nil, errors.New("global keys are only supported on Linux/X11")
+}
@@ -0,0 +1,119 @@
+package inputviewer
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"log"
+)
+
+// legacyButton is an N64 button from input_viewer.json before inputs were
+// made configurable.
+type legacyButton struct {
+	Type          inputType
+	ID, Dir       int
+	Pos, SheetPos image.Point
+	Color         color.RGBA
+}
+
+type legacyConfig struct {
+	A, B, Z, R, Start         *legacyButton
+	CUp, CRight, CDown, CLeft *legacyButton
+
+	J *struct {
+		IDX, IDY      int
+		Pos, SheetPos image.Point
+		Color         color.RGBA
+	}
+}
+
+// UnmarshalJSON reads the Inputs list, or converts the fixed N64 buttons of
+// older configurations if there is none.
+func (cfg *Config) UnmarshalJSON(data []byte) error {
+	type config Config // avoid recursion
+	var current config
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+	*cfg = Config(current)
+
+	if len(cfg.Inputs) > 0 {
+		return nil
+	}
+
+	var legacy legacyConfig
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	cfg.Inputs = legacy.inputs()
+	if len(cfg.Inputs) > 0 {
+		log.Printf("warning: input viewer configuration uses fixed buttons, convert it to an Inputs list")
+	}
+
+	return nil
+}
+
+func (legacy legacyConfig) inputs() []Input {
+	var ret []Input
+
+	buttons := []struct {
+		name   string
+		button *legacyButton
+	}{
+		{"A", legacy.A}, {"B", legacy.B}, {"Start", legacy.Start},
+		{"CUp", legacy.CUp}, {"CRight", legacy.CRight},
+		{"CDown", legacy.CDown}, {"CLeft", legacy.CLeft},
+	}
+	for _, v := range buttons {
+		if v.button == nil {
+			continue
+		}
+
+		ret = append(ret, Input{
+			Name:     v.name,
+			Type:     v.button.Type,
+			ID:       v.button.ID,
+			Dir:      v.button.Dir,
+			Pos:      v.button.Pos,
+			SheetPos: v.button.SheetPos,
+		})
+	}
+
+	// Z and R were drawn as a dot when pressed.
+	triggers := []struct {
+		name   string
+		button *legacyButton
+	}{{"Z", legacy.Z}, {"R", legacy.R}}
+	for _, v := range triggers {
+		if v.button == nil {
+			continue
+		}
+
+		ret = append(ret, Input{
+			Name:         v.name,
+			Type:         v.button.Type,
+			ID:           v.button.ID,
+			Dir:          v.button.Dir,
+			Pos:          v.button.Pos,
+			Size:         image.Point{2, 2},
+			Shape:        inputShapeRect,
+			HideReleased: true,
+			Color:        v.button.Color,
+		})
+	}
+
+	if legacy.J != nil {
+		ret = append(ret, Input{
+			Name:     "Stick",
+			Type:     inputTypeStick,
+			IDX:      legacy.J.IDX,
+			IDY:      legacy.J.IDY,
+			Pos:      legacy.J.Pos,
+			SheetPos: legacy.J.SheetPos,
+			Color:    legacy.J.Color,
+		})
+	}
+
+	return ret
+}
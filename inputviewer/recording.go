@@ -0,0 +1,351 @@
+package inputviewer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Recordings are gzipped, starting with recordingMagic and the name and type
+// of every input. Every tick is then stored as the time since the start of the
+// recording and the timer time in milliseconds (uvarint, varint), two bits per
+// input (available, pressed), and the axes of every stick as int8.
+const (
+	recordingMagic = "IVANREC2"
+	recordingDir   = "recordings"
+)
+
+// inputState is the state of every input during a tick, by input index.
+type inputState struct {
+	available []bool
+	pressed   []bool
+	axes      [][2]float64 // sticks only
+}
+
+func newInputState(inputs int) inputState {
+	return inputState{
+		available: make([]bool, inputs),
+		pressed:   make([]bool, inputs),
+		axes:      make([][2]float64, inputs),
+	}
+}
+
+type recorder struct {
+	file  *os.File
+	gz    *gzip.Writer
+	w     *bufio.Writer
+	start time.Time
+	clock func() time.Duration // timer time
+}
+
+// IsRecording returns true if the input is being recorded to a file.
+func (iv *InputViewer) IsRecording() bool {
+	return iv != nil && iv.recorder != nil
+}
+
+// ToggleRecording starts recording the input along with the time given by
+// clock, or stops the recording.
+func (iv *InputViewer) ToggleRecording(clock func() time.Duration) error {
+	if iv == nil {
+		return nil
+	}
+
+	if iv.recorder != nil {
+		iv.StopRecording()
+		return nil
+	}
+
+	return iv.StartRecording(clock)
+}
+
+// StartRecording records the input to a new file in the recordings directory
+// along with the time given by clock.
+func (iv *InputViewer) StartRecording(clock func() time.Duration) error {
+	if iv == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(recordingDir, 0o755); err != nil {
+		return fmt.Errorf("unable to create recordings directory: %w", err)
+	}
+
+	path := filepath.Join(recordingDir, time.Now().Format("2006-01-02_15-04-05")+".ivr")
+
+	return iv.startRecording(path, clock)
+}
+
+func (iv *InputViewer) startRecording(path string, clock func() time.Duration) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create recording: %w", err)
+	}
+
+	rec := &recorder{file: file, start: time.Now(), clock: clock}
+	rec.gz = gzip.NewWriter(file)
+	rec.w = bufio.NewWriter(rec.gz)
+
+	header := []byte(recordingMagic)
+	header = binary.AppendUvarint(header, uint64(len(iv.config.Inputs)))
+	for _, v := range iv.config.Inputs {
+		header = appendString(header, v.Name)
+		header = appendString(header, string(v.Type))
+	}
+
+	if _, err := rec.w.Write(header); err == nil {
+		err = rec.w.Flush()
+	}
+	if err != nil {
+		rec.gz.Close()
+		file.Close()
+		return fmt.Errorf("unable to write recording header: %w", err)
+	}
+
+	iv.recorder = rec
+	log.Printf("info: recording input to %s", path)
+
+	return nil
+}
+
+// appendString appends the length of str as an uvarint followed by str.
+func appendString(buf []byte, str string) []byte {
+	return append(binary.AppendUvarint(buf, uint64(len(str))), str...)
+}
+
+// StopRecording closes the current recording, if any.
+func (iv *InputViewer) StopRecording() {
+	if iv == nil || iv.recorder == nil {
+		return
+	}
+
+	rec := iv.recorder
+	iv.recorder = nil
+
+	err := errors.Join(rec.w.Flush(), rec.gz.Close(), rec.file.Close())
+	if err != nil {
+		log.Printf("error: unable to write recording: %s", err)
+		return
+	}
+
+	log.Printf("info: recording saved to %s", rec.file.Name())
+}
+
+func (iv *InputViewer) record() {
+	rec := iv.recorder
+
+	buf := binary.AppendUvarint(nil, uint64(time.Since(rec.start).Milliseconds()))
+	buf = binary.AppendVarint(buf, rec.clock().Milliseconds())
+
+	bits := make([]byte, (2*len(iv.config.Inputs)+7)/8)
+	for k := range iv.config.Inputs {
+		if iv.state.available[k] {
+			bits[2*k/8] |= 1 << (2 * k % 8)
+		}
+		if iv.state.pressed[k] {
+			bits[2*k/8] |= 1 << (2*k%8 + 1)
+		}
+	}
+	buf = append(buf, bits...)
+
+	for k, v := range iv.config.Inputs {
+		if v.Type == inputTypeStick {
+			x, y := clampAxis(iv.state.axes[k][0]), clampAxis(iv.state.axes[k][1])
+			buf = append(buf, byte(int8(x*127)), byte(int8(y*127)))
+		}
+	}
+
+	if _, err := rec.w.Write(buf); err != nil {
+		log.Printf("error: unable to write recording: %s", err)
+		iv.StopRecording()
+	}
+}
+
+// recordedInput is an input as listed in a recording header.
+type recordedInput struct {
+	Name string
+	Type inputType
+}
+
+// Recording reads back an input recording tick by tick.
+type Recording struct {
+	file *os.File
+	r    *bufio.Reader
+
+	inputs []recordedInput
+
+	elapsed, timer time.Duration
+	state          inputState
+}
+
+// OpenRecording opens a recording made with the same input viewer
+// configuration.
+func OpenRecording(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open recording: %w", err)
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to read recording: %w", err)
+	}
+
+	rec := &Recording{file: file, r: bufio.NewReader(gz)}
+	if err := rec.readHeader(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to read recording header: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (rec *Recording) Close() error {
+	return rec.file.Close()
+}
+
+func (rec *Recording) readHeader() error {
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(rec.r, magic); err != nil {
+		return err
+	}
+	if string(magic) != recordingMagic {
+		return errors.New("not an input recording")
+	}
+
+	count, err := binary.ReadUvarint(rec.r)
+	if err != nil {
+		return err
+	}
+
+	rec.inputs = make([]recordedInput, count)
+	for k := range rec.inputs {
+		name, err := readString(rec.r)
+		if err != nil {
+			return err
+		}
+
+		typ, err := readString(rec.r)
+		if err != nil {
+			return err
+		}
+
+		rec.inputs[k] = recordedInput{name, inputType(typ)}
+	}
+
+	rec.state = newInputState(len(rec.inputs))
+
+	return nil
+}
+
+// readString reads a string written by appendString.
+func readString(r *bufio.Reader) (string, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// checkInputs returns an error if the recording was made with other inputs.
+func (rec *Recording) checkInputs(inputs []Input) error {
+	if len(rec.inputs) != len(inputs) {
+		return fmt.Errorf("recording has %d inputs, configuration has %d", len(rec.inputs), len(inputs))
+	}
+
+	for k, v := range inputs {
+		if rec.inputs[k] != (recordedInput{v.Name, v.Type}) {
+			return fmt.Errorf(
+				"recorded input %d is %s %s, configuration has %s %s",
+				k+1, rec.inputs[k].Type, rec.inputs[k].Name, v.Type, v.Name,
+			)
+		}
+	}
+
+	return nil
+}
+
+// next reads the next tick.
+func (rec *Recording) next() error {
+	elapsed, err := binary.ReadUvarint(rec.r)
+	if err != nil {
+		return err
+	}
+
+	timer, err := binary.ReadVarint(rec.r)
+	if err != nil {
+		return err
+	}
+
+	rec.elapsed = time.Duration(elapsed) * time.Millisecond
+	rec.timer = time.Duration(timer) * time.Millisecond
+
+	bits := make([]byte, (2*len(rec.inputs)+7)/8)
+	if _, err := io.ReadFull(rec.r, bits); err != nil {
+		return err
+	}
+
+	for k := range rec.inputs {
+		rec.state.available[k] = bits[2*k/8]&(1<<(2*k%8)) != 0
+		rec.state.pressed[k] = bits[2*k/8]&(1<<(2*k%8+1)) != 0
+	}
+
+	for k, v := range rec.inputs {
+		if v.Type != inputTypeStick {
+			continue
+		}
+
+		var axes [2]int8
+		if err := binary.Read(rec.r, binary.LittleEndian, &axes); err != nil {
+			return err
+		}
+		rec.state.axes[k] = [2]float64{float64(axes[0]) / 127, float64(axes[1]) / 127}
+	}
+
+	return nil
+}
+
+// Timer returns the timer time of the current tick.
+func (rec *Recording) Timer() time.Duration {
+	return rec.timer
+}
+
+// UpdateFromRecording replaces the input with the next tick of the recording,
+// it returns false once the recording is over.
+func (iv *InputViewer) UpdateFromRecording(rec *Recording) bool {
+	if err := rec.checkInputs(iv.config.Inputs); err != nil {
+		log.Printf("error: %s", err)
+		return false
+	}
+
+	if err := rec.next(); err != nil {
+		if !errors.Is(err, io.EOF) {
+			log.Printf("error: unable to read recording: %s", err)
+		}
+		return false
+	}
+
+	iv.state = rec.state
+	iv.replaying = true
+	iv.sample(time.Time{}.Add(rec.elapsed))
+
+	return true
+}
+
+// clampAxis keeps a recorded axis value in the int8 range.
+func clampAxis(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
+}
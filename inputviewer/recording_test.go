@@ -0,0 +1,113 @@
+package inputviewer //nolint:testpackage // the recorder is only reachable from a running game.
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestInputViewer(inputs []Input) *InputViewer {
+	return &InputViewer{
+		config:  Config{Enabled: true, Inputs: inputs},
+		history: newHistory(historyConfig{}, image.Rectangle{}, len(inputs)),
+		state:   newInputState(len(inputs)),
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	inputs := []Input{
+		{Name: "A", Type: inputTypeButton},
+		{Name: "Stick", Type: inputTypeStick},
+		{Name: "Z", Type: inputTypeAxis},
+		{Name: "Start", Type: inputTypeKey},
+		{Name: "C", Type: inputTypeStick},
+	}
+
+	ticks := []inputState{
+		{
+			available: []bool{true, true, true, true, false},
+			pressed:   []bool{false, false, false, false, false},
+			axes:      [][2]float64{{}, {0, 0}, {}, {}, {}},
+		},
+		{
+			available: []bool{true, true, true, true, true},
+			pressed:   []bool{true, true, false, true, false},
+			axes:      [][2]float64{{}, {1, -1}, {}, {}, {-64.0 / 127, 32.0 / 127}},
+		},
+		{
+			available: []bool{false, true, true, false, true},
+			pressed:   []bool{false, false, true, false, true},
+			axes:      [][2]float64{{}, {-1, 1}, {}, {}, {0, 0}},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "test.ivr")
+	timer := -5 * time.Second
+
+	writer := newTestInputViewer(inputs)
+	if err := writer.startRecording(path, func() time.Duration { return timer }); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range ticks {
+		writer.state = v
+		writer.record()
+		timer += 1500 * time.Millisecond
+	}
+	writer.StopRecording()
+
+	rec, err := OpenRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Close()
+
+	reader := newTestInputViewer(inputs)
+	timer = -5 * time.Second
+	for k, v := range ticks {
+		if !reader.UpdateFromRecording(rec) {
+			t.Fatalf("tick %d: recording ended early", k)
+		}
+
+		if !reflect.DeepEqual(reader.state, v) {
+			t.Errorf("tick %d: expected %v, got %v", k, v, reader.state)
+		}
+		if rec.Timer() != timer {
+			t.Errorf("tick %d: expected timer %s, got %s", k, timer, rec.Timer())
+		}
+		timer += 1500 * time.Millisecond
+	}
+
+	if reader.UpdateFromRecording(rec) {
+		t.Error("expected the recording to be over")
+	}
+}
+
+func TestRecordingOtherInputs(t *testing.T) {
+	inputs := []Input{{Name: "A", Type: inputTypeButton}, {Name: "B", Type: inputTypeButton}}
+	path := filepath.Join(t.TempDir(), "test.ivr")
+
+	writer := newTestInputViewer(inputs)
+	if err := writer.startRecording(path, func() time.Duration { return 0 }); err != nil {
+		t.Fatal(err)
+	}
+	writer.record()
+	writer.StopRecording()
+
+	for _, v := range [][]Input{
+		{{Name: "A", Type: inputTypeButton}},
+		{{Name: "B", Type: inputTypeButton}, {Name: "A", Type: inputTypeButton}},
+		{{Name: "A", Type: inputTypeButton}, {Name: "B", Type: inputTypeKey}},
+	} {
+		rec, err := OpenRecording(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if newTestInputViewer(v).UpdateFromRecording(rec) {
+			t.Errorf("%v: expected the recording to be refused", v)
+		}
+		rec.Close()
+	}
+}
@@ -0,0 +1,93 @@
+package inputviewer
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// standardButtons are the names of the buttons of the standard gamepad
+// layout, as used in Input.Standard.
+var standardButtons = map[string]ebiten.StandardGamepadButton{
+	"RightBottom":      ebiten.StandardGamepadButtonRightBottom,
+	"RightRight":       ebiten.StandardGamepadButtonRightRight,
+	"RightLeft":        ebiten.StandardGamepadButtonRightLeft,
+	"RightTop":         ebiten.StandardGamepadButtonRightTop,
+	"FrontTopLeft":     ebiten.StandardGamepadButtonFrontTopLeft,
+	"FrontTopRight":    ebiten.StandardGamepadButtonFrontTopRight,
+	"FrontBottomLeft":  ebiten.StandardGamepadButtonFrontBottomLeft,
+	"FrontBottomRight": ebiten.StandardGamepadButtonFrontBottomRight,
+	"CenterLeft":       ebiten.StandardGamepadButtonCenterLeft,
+	"CenterRight":      ebiten.StandardGamepadButtonCenterRight,
+	"LeftStick":        ebiten.StandardGamepadButtonLeftStick,
+	"RightStick":       ebiten.StandardGamepadButtonRightStick,
+	"LeftTop":          ebiten.StandardGamepadButtonLeftTop,
+	"LeftBottom":       ebiten.StandardGamepadButtonLeftBottom,
+	"LeftLeft":         ebiten.StandardGamepadButtonLeftLeft,
+	"LeftRight":        ebiten.StandardGamepadButtonLeftRight,
+	"CenterCenter":     ebiten.StandardGamepadButtonCenterCenter,
+}
+
+// standardAxes are the names of the axes of the standard gamepad layout.
+var standardAxes = map[string]ebiten.StandardGamepadAxis{
+	"LeftStickHorizontal":  ebiten.StandardGamepadAxisLeftStickHorizontal,
+	"LeftStickVertical":    ebiten.StandardGamepadAxisLeftStickVertical,
+	"RightStickHorizontal": ebiten.StandardGamepadAxisRightStickHorizontal,
+	"RightStickVertical":   ebiten.StandardGamepadAxisRightStickVertical,
+}
+
+// standardSticks are the axes of the sticks of the standard gamepad layout.
+var standardSticks = map[string][2]ebiten.StandardGamepadAxis{
+	"LeftStick": {
+		ebiten.StandardGamepadAxisLeftStickHorizontal,
+		ebiten.StandardGamepadAxisLeftStickVertical,
+	},
+	"RightStick": {
+		ebiten.StandardGamepadAxisRightStickHorizontal,
+		ebiten.StandardGamepadAxisRightStickVertical,
+	},
+}
+
+// standardButtonName returns the name of a standard button.
+func standardButtonName(button ebiten.StandardGamepadButton) string {
+	for k, v := range standardButtons {
+		if v == button {
+			return k
+		}
+	}
+
+	return ""
+}
+
+// standardPressed reads the input from the standard layout, ok is false if
+// the input has no standard name or the gamepad has no standard layout.
+func (iv *InputViewer) standardPressed(in Input) (pressed, ok bool) {
+	if in.Standard == "" || !ebiten.IsStandardGamepadLayoutAvailable(iv.id) {
+		return false, false
+	}
+
+	switch in.Type {
+	case inputTypeButton:
+		button, ok := standardButtons[in.Standard]
+		if !ok {
+			return false, false
+		}
+
+		return ebiten.StandardGamepadButtonValue(iv.id, button) > in.deadZone(), true
+	case inputTypeAxis:
+		axis, ok := standardAxes[in.Standard]
+		if !ok {
+			return false, false
+		}
+
+		return axisPressed(ebiten.StandardGamepadAxisValue(iv.id, axis), in.Dir, in.deadZone()), true
+	}
+
+	return false, false
+}
+
+// standardStick returns the axes of a stick from the standard layout.
+func (iv *InputViewer) standardStick(in Input) (x, y float64, ok bool) {
+	axes, ok := standardSticks[in.Standard]
+	if !ok || !ebiten.IsStandardGamepadLayoutAvailable(iv.id) {
+		return 0, 0, false
+	}
+
+	return ebiten.StandardGamepadAxisValue(iv.id, axes[0]), ebiten.StandardGamepadAxisValue(iv.id, axes[1]), true
+}
@@ -0,0 +1,92 @@
+// Package x11 reads the global keyboard state from the X server, it works
+// regardless of the focused window.
+package x11
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Conn is a connection to the X server.
+type Conn struct {
+	conn     *xgb.Conn
+	keycodes map[xproto.Keysym][]xproto.Keycode
+}
+
+// Open connects to the display set in $DISPLAY.
+func Open() (*Conn, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the X server: %w", err)
+	}
+
+	c := &Conn{conn: conn}
+	if err := c.loadKeyboardMapping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Conn) Close() error {
+	c.conn.Close()
+	return nil
+}
+
+func (c *Conn) loadKeyboardMapping() error {
+	setup := xproto.Setup(c.conn)
+	count := int(setup.MaxKeycode) - int(setup.MinKeycode) + 1
+
+	reply, err := xproto.GetKeyboardMapping(c.conn, setup.MinKeycode, byte(count)).Reply()
+	if err != nil {
+		return fmt.Errorf("unable to get keyboard mapping: %w", err)
+	}
+
+	perKeycode := int(reply.KeysymsPerKeycode)
+	if perKeycode == 0 {
+		return errors.New("empty keyboard mapping")
+	}
+
+	c.keycodes = make(map[xproto.Keysym][]xproto.Keycode)
+	for k, keysym := range reply.Keysyms {
+		if keysym == 0 {
+			continue
+		}
+
+		keycode := setup.MinKeycode + xproto.Keycode(k/perKeycode)
+		c.keycodes[keysym] = append(c.keycodes[keysym], keycode)
+	}
+
+	return nil
+}
+
+// Keymap is the state of every keycode, one bit per keycode.
+type Keymap [32]byte
+
+// QueryKeymap returns the current keyboard state.
+func (c *Conn) QueryKeymap() (Keymap, error) {
+	var keymap Keymap
+
+	reply, err := xproto.QueryKeymap(c.conn).Reply()
+	if err != nil {
+		return keymap, fmt.Errorf("unable to query keymap: %w", err)
+	}
+
+	copy(keymap[:], reply.Keys)
+	return keymap, nil
+}
+
+// IsPressed returns true if a key producing the keysym is pressed.
+func (c *Conn) IsPressed(keymap Keymap, keysym uint32) bool {
+	for _, keycode := range c.keycodes[xproto.Keysym(keysym)] {
+		if keymap[keycode/8]&(1<<(keycode%8)) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
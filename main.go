@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"flag"
 	_ "image/png"
 	"log"
 	"os"
@@ -18,6 +19,17 @@ var Version = "unknown"
 func main() {
 	log.Printf("ivan %s\n", Version)
 
+	replay := flag.String("replay", "", "render the given input recording to PNG frames and exit")
+	out := flag.String("out", "frames", "output directory of the rendered frames")
+	flag.Parse()
+
+	if *replay != "" {
+		// Paths are relative to the working directory, not the executable.
+		if err := absPaths(replay, out); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	chdirToExecutableDir()
 
 	ebiten.SetWindowTitle("Ivan")
@@ -29,6 +41,13 @@ func main() {
 		ebiten.SetWindowDecorated(false)
 	}
 
+	if *replay != "" {
+		if err := renderRecording(*replay, *out); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ivan, err := NewApp()
 	if err != nil {
 		log.Fatal(err)
@@ -55,3 +74,15 @@ func chdirToExecutableDir() {
 		log.Fatal(err)
 	}
 }
+
+func absPaths(paths ...*string) error {
+	for _, v := range paths {
+		abs, err := filepath.Abs(*v)
+		if err != nil {
+			return err
+		}
+		*v = abs
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Command fakemultiworld is a local stand-in for a multiworld server, to run
+// the multiworld feed without a session. Every line read on the standard
+// input, formatted as "sender: item name", is sent to all connected players.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+type hello struct {
+	Player   string
+	Received int
+}
+
+type item struct {
+	Index int
+	From  string
+	Item  string
+}
+
+// server shares the same received items between all players.
+type server struct {
+	mu      sync.Mutex
+	items   []item
+	clients map[*json.Encoder]struct{}
+}
+
+func main() {
+	address := flag.String("address", "127.0.0.1:28922", "TCP address to listen on")
+	flag.Parse()
+
+	ln, err := net.Listen("tcp", *address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ln.Close()
+
+	log.Printf("listening on %s, send items as 'sender: item name'", ln.Addr())
+
+	s := &server{clients: make(map[*json.Encoder]struct{})}
+	go s.readInput()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go s.serve(conn)
+	}
+}
+
+func (s *server) readInput() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		from, name, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			log.Printf("error: expected 'sender: item name'")
+			continue
+		}
+
+		s.send(strings.TrimSpace(from), strings.TrimSpace(name))
+	}
+}
+
+func (s *server) send(from, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := item{Index: len(s.items), From: from, Item: name}
+	s.items = append(s.items, v)
+	for enc := range s.clients {
+		if err := enc.Encode(v); err != nil {
+			log.Printf("error: %s", err)
+		}
+	}
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var h hello
+	if err := json.NewDecoder(conn).Decode(&h); err != nil {
+		log.Printf("error: invalid hello: %s", err)
+		return
+	}
+
+	log.Printf("%s connected, received %d items", h.Player, h.Received)
+
+	enc := json.NewEncoder(conn)
+	s.mu.Lock()
+	for _, v := range s.items[min(max(0, h.Received), len(s.items)):] {
+		if err := enc.Encode(v); err != nil {
+			log.Printf("error: %s", err)
+		}
+	}
+	s.clients[enc] = struct{}{}
+	s.mu.Unlock()
+
+	// Wait for the player to leave.
+	_, _ = conn.Read(make([]byte, 1))
+
+	s.mu.Lock()
+	delete(s.clients, enc)
+	s.mu.Unlock()
+
+	log.Printf("%s disconnected", h.Player)
+}
@@ -0,0 +1,217 @@
+// Package multiworld receives the items sent by other players of a multiworld
+// session.
+//
+// The server speaks JSON lines over TCP. Once connected, the client sends
+// {"Player": "name", "Received": n} and the server answers with every item
+// received by that player from the n-th one on, then with new items as they
+// are sent: {"Index": n, "From": "sender", "Item": "item name"}.
+package multiworld
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+const (
+	dialTimeout    = 2 * time.Second
+	reconnectDelay = 5 * time.Second
+)
+
+type Config struct {
+	Enabled bool
+
+	Address string // host:port of the multiworld server
+	Player  string // name of this player in the session
+
+	// Items maps item names sent by the server to tracker items, names
+	// missing from the map are used as is. When several items are listed,
+	// the first one not obtained yet is used, eg. for bottles.
+	Items map[string][]string
+}
+
+// Item is an item received from another player.
+type Item struct {
+	Index int    // position in the items received by this player
+	From  string // name of the sender
+	Item  string // item name as sent by the server
+}
+
+type hello struct {
+	Player   string
+	Received int
+}
+
+// Client receives items in the background and reconnects when the connection
+// is lost.
+type Client struct {
+	config Config
+	items  chan Item
+	reset  chan struct{}
+	stop   chan struct{}
+}
+
+// New connects to the multiworld server, asking for the items received after
+// the given count. It returns nil if the multiworld feed is disabled.
+func New(config Config, received int) *Client {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Player == "" {
+		log.Printf("error: multiworld disabled: no player name")
+		return nil
+	}
+
+	c := &Client{
+		config: config,
+		items:  make(chan Item, 64),
+		reset:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	go c.run(received)
+
+	return c
+}
+
+// Close disconnects from the server.
+func (c *Client) Close() {
+	if c == nil {
+		return
+	}
+
+	close(c.stop)
+}
+
+// Reset asks the server for every received item again, eg. after the tracker
+// was reset.
+func (c *Client) Reset() {
+	if c == nil {
+		return
+	}
+
+	select {
+	case c.reset <- struct{}{}:
+	default:
+	}
+}
+
+// Poll returns the items received since the last call.
+func (c *Client) Poll() []Item {
+	if c == nil {
+		return nil
+	}
+
+	var ret []Item
+	for {
+		select {
+		case item := <-c.items:
+			ret = append(ret, item)
+		default:
+			return ret
+		}
+	}
+}
+
+var (
+	errClosed = errors.New("client closed")
+	errReset  = errors.New("feed reset")
+)
+
+func (c *Client) run(received int) {
+	var failing bool
+
+	for {
+		conn, err := net.DialTimeout("tcp", c.config.Address, dialTimeout)
+		if err == nil {
+			failing = false
+			log.Printf("info: multiworld: connected to %s as %s", c.config.Address, c.config.Player)
+
+			received, err = c.session(conn, received)
+			switch {
+			case errors.Is(err, errClosed):
+				return
+			case errors.Is(err, errReset):
+				continue
+			}
+		}
+
+		if !failing {
+			log.Printf("warning: multiworld: %s", err)
+			failing = true
+		}
+
+		select {
+		case <-c.stop:
+			return
+		case <-c.reset:
+			received = 0
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// session receives items until the connection is lost, the feed is reset, or
+// the client is closed. It returns the count of received items.
+func (c *Client) session(conn net.Conn, received int) (int, error) {
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(hello{c.config.Player, received}); err != nil {
+		return received, fmt.Errorf("unable to send hello: %w", err)
+	}
+
+	items, errs := make(chan Item), make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go read(conn, items, errs, done)
+
+	for {
+		select {
+		case <-c.stop:
+			return received, errClosed
+		case <-c.reset:
+			return 0, errReset
+		case err := <-errs:
+			return received, fmt.Errorf("connection lost: %w", err)
+		case item := <-items:
+			if item.Index < received {
+				continue // already received
+			}
+			received = item.Index + 1
+
+			select {
+			case c.items <- item:
+			case <-c.stop:
+				return received, errClosed
+			}
+		}
+	}
+}
+
+func read(conn net.Conn, items chan<- Item, errs chan<- error, done <-chan struct{}) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var item Item
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			errs <- fmt.Errorf("invalid message: %w", err)
+			return
+		}
+
+		select {
+		case items <- item:
+		case <-done:
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs <- err
+		return
+	}
+
+	errs <- errors.New("closed by the server")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"ivan/inputviewer"
+	"ivan/theme"
+	"ivan/tracker"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// Replay renders an input recording through the input viewer, one PNG frame
+// per recorded tick.
+type Replay struct {
+	inputViewer *inputviewer.InputViewer
+	recording   *inputviewer.Recording
+	theme       *theme.Theme
+	outDir      string
+
+	frame  *ebiten.Image
+	pixels []byte
+	count  int
+}
+
+func renderRecording(path, outDir string) error {
+	cfg, err := tracker.NewConfigFromDir(configDir)
+	if err != nil {
+		return fmt.Errorf("unable to load config: %w", err)
+	}
+
+	th, err := theme.Load(themesDir, cfg.Theme.Name)
+	if err != nil {
+		return err
+	}
+
+	recording, err := inputviewer.OpenRecording(path)
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("unable to create output directory: %w", err)
+	}
+
+	viewerCfg := cfg.InputViewer
+	viewerCfg.Enabled = true
+	viewerCfg.GlobalKeys = false
+
+	size := cfg.Layout.WindowSize()
+	ebiten.SetWindowSize(size.X, size.Y)
+
+	replay := &Replay{
+		inputViewer: inputviewer.NewInputViewer(viewerCfg, cfg.Layout.InputHistory, th),
+		recording:   recording,
+		theme:       th,
+		outDir:      outDir,
+		frame:       ebiten.NewImage(size.X, size.Y),
+		pixels:      make([]byte, 4*size.X*size.Y),
+	}
+
+	if err := ebiten.RunGame(replay); err != nil && !errors.Is(err, ebiten.Termination) {
+		return err
+	}
+
+	log.Printf("info: rendered %d frames to %s", replay.count, outDir)
+
+	return nil
+}
+
+func (replay *Replay) Update() error {
+	if !replay.inputViewer.UpdateFromRecording(replay.recording) {
+		return ebiten.Termination
+	}
+
+	replay.frame.Clear()
+	replay.inputViewer.Draw(replay.frame)
+	replay.frame.ReadPixels(replay.pixels)
+
+	bounds := replay.frame.Bounds()
+	img := &image.RGBA{Pix: replay.pixels, Stride: 4 * bounds.Dx(), Rect: bounds}
+
+	path := filepath.Join(replay.outDir, fmt.Sprintf("%06d.png", replay.count))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create frame: %w", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("unable to write frame: %w", err)
+	}
+	replay.count++
+
+	return nil
+}
+
+func (replay *Replay) Draw(screen *ebiten.Image) {
+	screen.DrawImage(replay.frame, nil)
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(replay.theme.Text)
+	str := fmt.Sprintf("frame %d, timer %s", replay.count, replay.recording.Timer())
+	text.Draw(screen, str, replay.theme.SmallFace(), op)
+}
+
+func (replay *Replay) Layout(w, h int) (int, int) {
+	return replay.frame.Bounds().Dx(), replay.frame.Bounds().Dy()
+}
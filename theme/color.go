@@ -0,0 +1,41 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+)
+
+// Color is a color.RGBA written as "#RRGGBB" or "#RRGGBBAA" in theme files.
+type Color color.RGBA
+
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return color.RGBA(c).RGBA()
+}
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	var ret Color
+	switch len(str) {
+	case len("#RRGGBB"):
+		ret.A = 0xFF
+		_, err := fmt.Sscanf(str, "#%02x%02x%02x", &ret.R, &ret.G, &ret.B)
+		if err != nil {
+			return fmt.Errorf("invalid color '%s': %w", str, err)
+		}
+	case len("#RRGGBBAA"):
+		_, err := fmt.Sscanf(str, "#%02x%02x%02x%02x", &ret.R, &ret.G, &ret.B, &ret.A)
+		if err != nil {
+			return fmt.Errorf("invalid color '%s': %w", str, err)
+		}
+	default:
+		return fmt.Errorf("invalid color '%s', expected #RRGGBB or #RRGGBBAA", str)
+	}
+
+	*c = ret
+	return nil
+}
@@ -0,0 +1,285 @@
+// Package theme holds the fonts and colors used to draw the tracker and the
+// timer, loaded from the JSON files of a themes directory.
+package theme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// DefaultName is the theme used when none is selected.
+const DefaultName = "default"
+
+// Font is a TTF or OTF font file, eg. "assets/font.ttf", or one of the
+// embedded "goregular" and "gomono" fonts, used when File is empty.
+type Font struct {
+	File string `json:",omitempty"`
+	Size float64
+}
+
+// HintStyle overrides the colors of a hint category, nil colors being kept.
+type HintStyle struct {
+	Background *Color `json:",omitempty"` // in the hint panel
+	Map        *Color `json:",omitempty"` // on the map
+	Text       *Color `json:",omitempty"`
+}
+
+type Theme struct {
+	Name string `json:"-"`
+
+	Font, SmallFont Font
+	TimerFont       Font
+
+	Text      Color // default text color
+	Highlight Color // active item slot and medallions during input
+	Overlay   Color // behind text drawn over other elements
+	Tooltip   Color
+
+	PanelBackground Color // map and lists
+	PanelHeader     Color // list headers and unread gossip regions
+	Dimmed          Color // read gossip regions, items missing from the tracker
+	UnknownRegion   Color // on the map
+
+	TimerRunning, TimerPaused Color
+
+	DungeonVanilla, DungeonMQ, DungeonDuplicate Color
+
+	HintText, HintCheckedText        Color
+	HintSelection                    Color
+	HintDuplicate, HintContradiction Color
+	Hints                            map[string]HintStyle // hint category name to its style
+
+	face, smallFace, timerFace *text.GoTextFace
+}
+
+// Default returns the built-in theme, loaded themes only override the values
+// they set.
+func Default() *Theme {
+	return &Theme{
+		Name: DefaultName,
+
+		Font:      Font{Size: 20},
+		SmallFont: Font{Size: 13},
+		TimerFont: Font{Size: 32},
+
+		Text:      Color{0xFF, 0xFF, 0xFF, 0xFF},
+		Highlight: Color{0xFF, 0xFF, 0xFF, 0x50},
+		Overlay:   Color{0x00, 0x00, 0x00, 0xC0},
+		Tooltip:   Color{0x00, 0x00, 0x00, 0xE0},
+
+		PanelBackground: Color{0x20, 0x20, 0x20, 0xFF},
+		PanelHeader:     Color{0xFF, 0xE6, 0x99, 0xFF},
+		Dimmed:          Color{0x80, 0x80, 0x80, 0xFF},
+		UnknownRegion:   Color{0x80, 0x80, 0x80, 0xFF},
+
+		TimerRunning: Color{0xFF, 0xFF, 0xFF, 0xFF},
+		TimerPaused:  Color{0xDC, 0xAC, 0x26, 0xFF},
+
+		DungeonVanilla:   Color{0x9F, 0xD8, 0xFF, 0xFF},
+		DungeonMQ:        Color{0xDC, 0xAC, 0x26, 0xFF},
+		DungeonDuplicate: Color{0xFF, 0x6D, 0x6D, 0xFF},
+
+		HintText:          Color{0x00, 0x00, 0x00, 0xFF},
+		HintCheckedText:   Color{0x50, 0x50, 0x50, 0xFF},
+		HintSelection:     Color{0xFF, 0xFF, 0xFF, 0xFF},
+		HintDuplicate:     Color{0x30, 0x60, 0xFF, 0xFF},
+		HintContradiction: Color{0xFF, 0x00, 0xFF, 0xFF},
+	}
+}
+
+// Load reads the theme of the given name from dir, over the default theme,
+// and loads its fonts.
+func Load(dir, name string) (*Theme, error) {
+	if name == "" {
+		name = DefaultName
+	}
+
+	theme := Default()
+	theme.Name = name
+
+	path := filepath.Join(dir, name+".json")
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err) && name == DefaultName:
+		// The built-in theme.
+	case err != nil:
+		return nil, fmt.Errorf("unable to open theme '%s': %w", name, err)
+	default:
+		if err := json.Unmarshal(data, theme); err != nil {
+			return nil, fmt.Errorf("unable to decode json in '%s': %w", path, err)
+		}
+	}
+
+	if err := theme.loadFonts(); err != nil {
+		return nil, fmt.Errorf("theme '%s': %w", name, err)
+	}
+
+	return theme, nil
+}
+
+// List returns the sorted names of the themes in dir, including the default
+// theme.
+func List(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{DefaultName}
+	for _, v := range paths {
+		name := strings.TrimSuffix(filepath.Base(v), ".json")
+		if name != DefaultName {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// builtinFonts are the embedded fonts, usable as a Font File.
+var builtinFonts = map[string][]byte{
+	"goregular": goregular.TTF,
+	"gomono":    gomono.TTF,
+}
+
+func (theme *Theme) loadFonts() error {
+	sources := make(map[string]*text.GoTextFaceSource)
+	load := func(font Font, builtin string) (*text.GoTextFace, error) {
+		if font.Size <= 0 {
+			return nil, fmt.Errorf("invalid font size %g", font.Size)
+		}
+
+		file := font.File
+		if file == "" {
+			file = builtin
+		}
+
+		src, ok := sources[file]
+		if !ok {
+			data, ok := builtinFonts[file]
+			if !ok {
+				var err error
+				if data, err = os.ReadFile(file); err != nil {
+					return nil, fmt.Errorf("unable to read font: %w", err)
+				}
+			}
+
+			var err error
+			if src, err = text.NewGoTextFaceSource(bytes.NewReader(data)); err != nil {
+				return nil, fmt.Errorf("unable to load font '%s': %w", file, err)
+			}
+			sources[file] = src
+		}
+
+		return &text.GoTextFace{Source: src, Size: font.Size}, nil
+	}
+
+	var err error
+	if theme.face, err = load(theme.Font, "goregular"); err != nil {
+		return err
+	}
+	if theme.smallFace, err = load(theme.SmallFont, "goregular"); err != nil {
+		return err
+	}
+	if theme.timerFace, err = load(theme.TimerFont, "gomono"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Face returns the face of the main font.
+func (theme *Theme) Face() *text.GoTextFace {
+	return theme.face
+}
+
+// SmallFace returns the face of the small font, used for most text.
+func (theme *Theme) SmallFace() *text.GoTextFace {
+	return theme.smallFace
+}
+
+// TimerFace returns the face of the timer font.
+func (theme *Theme) TimerFace() *text.GoTextFace {
+	return theme.timerFace
+}
+
+// HintBackground returns the background of the hints of the given category,
+// or fallback if the theme does not override it.
+func (theme *Theme) HintBackground(category string, fallback color.RGBA) color.RGBA {
+	if style, ok := theme.Hints[category]; ok && style.Background != nil {
+		return color.RGBA(*style.Background)
+	}
+
+	return fallback
+}
+
+// HintMapColor returns the map color of the given hint category, or fallback
+// if the theme does not override it.
+func (theme *Theme) HintMapColor(category string, fallback color.RGBA) color.RGBA {
+	if style, ok := theme.Hints[category]; ok && style.Map != nil {
+		return color.RGBA(*style.Map)
+	}
+
+	return fallback
+}
+
+// HintTextColor returns the text color of the hints of the given category.
+func (theme *Theme) HintTextColor(category string) Color {
+	if style, ok := theme.Hints[category]; ok && style.Text != nil {
+		return *style.Text
+	}
+
+	return theme.HintText
+}
+
+// Config selects the theme to use.
+type Config struct {
+	Name string
+}
+
+// Selected returns the name of the theme last selected at runtime, or
+// fallback if none was saved.
+func Selected(fallback string) string {
+	data, err := os.ReadFile(getSelectionPath())
+	if err != nil {
+		return fallback
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil || cfg.Name == "" {
+		return fallback
+	}
+
+	return cfg.Name
+}
+
+// SaveSelected saves the name of the theme selected at runtime next to the
+// tracker state, leaving the config directory untouched.
+func SaveSelected(name string) error {
+	data, err := json.Marshal(Config{Name: name})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(getSelectionPath(), data, 0o600)
+}
+
+func getSelectionPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = "./"
+	}
+
+	return filepath.Join(dir, "ivan.theme.json")
+}
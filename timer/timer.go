@@ -1,11 +1,10 @@
 package timer
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"image"
-	"image/color"
+	"ivan/theme"
 	"math"
 	"os"
 	"path/filepath"
@@ -13,11 +12,8 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"golang.org/x/image/font/gofont/gomono"
 )
 
-const timeFontSize = 32
-
 type timerState int
 
 const (
@@ -30,31 +26,30 @@ type Timer struct {
 	startedAt, pausedAt time.Time
 	state               timerState
 
-	font     text.Face
-	pos      image.Point
-	size     image.Point
-	timeSize image.Point
+	theme     *theme.Theme
+	pos       image.Point
+	size      image.Point
+	timeSize  image.Point
+	dashWidth int // width of the initial "-"
 }
 
-func New(dimensions image.Rectangle) (*Timer, error) {
-	ttf, err := text.NewGoTextFaceSource(bytes.NewReader(gomono.TTF))
-	if err != nil {
-		return nil, fmt.Errorf("unable to load font: %w", err)
+func New(dimensions image.Rectangle, th *theme.Theme) *Timer {
+	timer := &Timer{
+		pos:  dimensions.Min,
+		size: dimensions.Size(),
 	}
+	timer.SetTheme(th)
 
-	font := &text.GoTextFace{
-		Source: ttf,
-		Size:   timeFontSize,
-	}
+	return timer
+}
 
-	w, h := text.Measure(format(time.Duration(0)), font, 0)
+// SetTheme changes the font and colors of the timer.
+func (timer *Timer) SetTheme(th *theme.Theme) {
+	timer.theme = th
 
-	return &Timer{
-		font:     font,
-		pos:      dimensions.Min,
-		size:     dimensions.Size(),
-		timeSize: image.Point{int(math.Ceil(w)), int(math.Ceil(h))},
-	}, nil
+	w, h := text.Measure(format(time.Duration(0)), th.TimerFace(), 0)
+	timer.timeSize = image.Point{int(math.Ceil(w)), int(math.Ceil(h))}
+	timer.dashWidth = int(math.Ceil(text.Advance("-", th.TimerFace())))
 }
 
 func format(d time.Duration) string {
@@ -76,24 +71,21 @@ func (timer *Timer) Draw(screen *ebiten.Image) {
 	var str string
 	switch timer.state {
 	case stateInitial:
-		// HARDCODED, time size is cached and I don't want to compute this
-		pos.X = ((timer.size.X - 19) / 2)
+		pos.X = timer.pos.X + (timer.size.X-timer.dashWidth)/2
 		str = "-"
-	case stateRunning:
-		str = format(time.Since(timer.startedAt).Round(time.Millisecond))
-	case statePaused:
-		str = format(timer.pausedAt.Sub(timer.startedAt).Round(time.Millisecond))
+	case stateRunning, statePaused:
+		str = format(timer.Elapsed().Round(time.Millisecond))
 	}
 
-	textColor := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	textColor := timer.theme.TimerRunning
 	if timer.state == statePaused {
-		textColor = color.RGBA{0xDC, 0xAC, 0x26, 0xFF}
+		textColor = timer.theme.TimerPaused
 	}
 
 	op := &text.DrawOptions{}
 	op.GeoM.Translate(float64(pos.X), float64(pos.Y))
 	op.ColorScale.ScaleWithColor(textColor)
-	text.Draw(screen, str, timer.font, op)
+	text.Draw(screen, str, timer.theme.TimerFace(), op)
 }
 
 func (timer *Timer) Toggle() {
@@ -110,6 +102,18 @@ func (timer *Timer) Toggle() {
 	}
 }
 
+// Elapsed returns the time shown by the timer, zero if it was not started.
+func (timer *Timer) Elapsed() time.Duration {
+	switch timer.state {
+	case stateRunning:
+		return time.Since(timer.startedAt)
+	case statePaused:
+		return timer.pausedAt.Sub(timer.startedAt)
+	}
+
+	return 0
+}
+
 func (timer *Timer) Reset() {
 	timer.state = stateInitial
 }
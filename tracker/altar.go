@@ -0,0 +1,167 @@
+package tracker
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"strings"
+	"unicode"
+)
+
+const medallionSuffix = " medallion"
+
+// ParseAltar reads the child and/or adult altar text of the Temple of Time and
+// sets the dungeon of every stone and medallion it mentions.
+// It returns the names of the stones and medallions it could not match.
+func (tracker *Tracker) ParseAltar(str string) []string {
+	found := make(map[string]struct{}, 9)
+
+	for _, clause := range strings.FieldsFunc(str, func(r rune) bool {
+		return r == '.' || r == ',' || r == ';' || r == '\n'
+	}) {
+		reward, dungeon := tracker.parseAltarClause(clause)
+		if reward == "" || dungeon == "" {
+			continue
+		}
+
+		tracker.setDungeon(tracker.getItemIndexByName(reward), dungeon)
+		found[reward] = struct{}{}
+	}
+
+	var missing []string
+	for _, v := range tracker.getMedallionNames() {
+		if _, ok := found[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+
+	return missing
+}
+
+// parseAltarClause finds a stone or medallion and the dungeon holding it in a
+// single sentence, eg. "The Forest Medallion is held within the Fire Temple"
+// or the shorter "forest fire".
+func (tracker *Tracker) parseAltarClause(clause string) (string, string) {
+	lower := strings.ToLower(clause)
+
+	reward, at, length := tracker.findAltarReward(lower)
+	if reward == "" {
+		return "", ""
+	}
+
+	// Remove the reward so "forest" in "Forest Medallion" is not the dungeon.
+	rest := lower[:at] + " " + lower[at+length:]
+
+	dungeon, best := "", -1
+	for _, v := range tracker.cfg.Dungeons {
+		for _, keyword := range v.AltarKeywords {
+			if i := indexWord(rest, strings.ToLower(keyword)); i >= 0 && (best < 0 || i < best) {
+				dungeon, best = v.Name, i
+			}
+		}
+	}
+
+	return reward, dungeon
+}
+
+// findAltarReward returns the first stone or medallion name found in str and
+// its position. Full names are preferred, then stones' last word
+// ("emerald"), then a bare medallion element ("light").
+func (tracker *Tracker) findAltarReward(str string) (string, int, int) {
+	names := tracker.getMedallionNames()
+
+	for _, pass := range []func(string) string{
+		strings.ToLower,
+		func(name string) string {
+			if strings.HasSuffix(strings.ToLower(name), medallionSuffix) {
+				return ""
+			}
+			fields := strings.Fields(strings.ToLower(name))
+			return fields[len(fields)-1]
+		},
+		func(name string) string {
+			lower := strings.ToLower(name)
+			if !strings.HasSuffix(lower, medallionSuffix) {
+				return ""
+			}
+			return strings.TrimSuffix(lower, medallionSuffix)
+		},
+	} {
+		reward, best, length := "", -1, 0
+		for _, name := range names {
+			keyword := pass(name)
+			if keyword == "" {
+				continue
+			}
+
+			if i := indexWord(str, keyword); i >= 0 && (best < 0 || i < best) {
+				reward, best, length = name, i, len(keyword)
+			}
+		}
+
+		if reward != "" {
+			return reward, best, length
+		}
+	}
+
+	return "", -1, 0
+}
+
+// indexWord returns the index of the first occurrence of word in str that is
+// not part of a longer word, or -1.
+func indexWord(str, word string) int {
+	isLetter := func(s string, i int) bool {
+		return i >= 0 && i < len(s) && unicode.IsLetter(rune(s[i]))
+	}
+
+	for offset := 0; offset < len(str); {
+		i := strings.Index(str[offset:], word)
+		if i < 0 {
+			return -1
+		}
+		i += offset
+
+		if !isLetter(str, i-1) && !isLetter(str, i+len(word)) {
+			return i
+		}
+		offset = i + 1
+	}
+
+	return -1
+}
+
+// reportAltar displays the result of an altar parse.
+func (tracker *Tracker) reportAltar(missing []string) {
+	if len(missing) == 0 {
+		tracker.setMessage("altar: all dungeons set")
+		return
+	}
+
+	msg := fmt.Sprintf("altar: no dungeon for %s", strings.Join(missing, ", "))
+	log.Printf("warning: %s", msg)
+	tracker.setMessage(msg)
+}
+
+// DropFiles parses text files dropped on the window as altar text.
+func (tracker *Tracker) DropFiles(files fs.FS) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		log.Printf("error: unable to read dropped files: %s", err)
+		return
+	}
+
+	for _, v := range entries {
+		if v.IsDir() || path.Ext(v.Name()) != ".txt" {
+			continue
+		}
+
+		buf, err := fs.ReadFile(files, v.Name())
+		if err != nil {
+			log.Printf("error: unable to read dropped file '%s': %s", v.Name(), err)
+			continue
+		}
+
+		tracker.reportAltar(tracker.ParseAltar(string(buf)))
+	}
+}
@@ -0,0 +1,59 @@
+package tracker //nolint:testpackage // the parsers under test are unexported.
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindAltarReward(t *testing.T) {
+	tracker := newTestTracker(t)
+
+	for _, v := range []struct {
+		str, reward string
+		at          int
+	}{
+		{"the forest medallion is held within the fire temple", "Forest Medallion", 4},
+		{"the kokiri emerald is in jabu", "Kokiri Emerald", 4},
+		{"ruby deku", "Goron Ruby", 0},
+		{"deku light", "Light Medallion", 5},
+		{"fire forest medallion", "Forest Medallion", 5},
+		{"shadowy spirit", "Spirit Medallion", 8},
+		{"nothing here", "", -1},
+	} {
+		reward, at, _ := tracker.findAltarReward(v.str)
+		if reward != v.reward || at != v.at {
+			t.Errorf("%q: expected %q at %d, got %q at %d", v.str, v.reward, v.at, reward, at)
+		}
+	}
+}
+
+func TestParseAltar(t *testing.T) {
+	tracker := newTestTracker(t)
+
+	missing := tracker.ParseAltar(
+		"The Kokiri Emerald is in Link's Pocket. ruby dodongo; sapphire jabu\n" +
+			"forest fire, fire forest, The Water Medallion is held within the Water Temple.\n" +
+			"light deku, spirit shadow, shadow nowhere",
+	)
+
+	expected := map[string]string{
+		"Kokiri Emerald":   "Free",
+		"Goron Ruby":       "Dodongo's Cavern",
+		"Zora Sapphire":    "Jabu Jabu",
+		"Forest Medallion": "Fire Temple",
+		"Fire Medallion":   "Forest Temple",
+		"Water Medallion":  "Water Temple",
+		"Light Medallion":  "Deku Tree",
+		"Spirit Medallion": "Shadow Temple",
+	}
+	for name, dungeon := range expected {
+		item := tracker.items[tracker.getItemIndexByName(name)]
+		if actual, _ := tracker.getDungeon(item.DungeonIndex); actual.Name != dungeon {
+			t.Errorf("%s: expected %q, got %q", name, dungeon, actual.Name)
+		}
+	}
+
+	if !slices.Equal(missing, []string{"Shadow Medallion"}) {
+		t.Errorf("expected Shadow Medallion to be missing, got %v", missing)
+	}
+}
@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"ivan/autotracker"
+	"log"
+	"sort"
+)
+
+// belowItemState returns true if the item has less than the read state,
+// auto-tracking never takes away an item marked by hand.
+// Countable items are only upgraded by whole steps, up to CountMax.
+func (item *Item) belowItemState(read autotracker.ItemState) bool {
+	switch {
+	case !read.Enabled:
+		return false
+	case !item.Enabled:
+		return true
+	case item.IsCountable():
+		target := min(read.Count, item.CountMax)
+		return item.Count+item.CountStep <= target ||
+			(target == item.CountMax && item.Count < target)
+	}
+
+	maxIndex := max(len(item.ItemProgression), len(item.CapacityProgression)) - 1
+	return item.UpgradeIndex < min(read.UpgradeIndex, maxIndex)
+}
+
+// ApplyItemStates upgrades the items to the states read by the auto-tracker
+// in a single undo step, it returns true if any item changed.
+func (tracker *Tracker) ApplyItemStates(states map[string]autotracker.ItemState) bool {
+	names := make([]string, 0, len(states))
+	for k := range states {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var changed bool
+	for _, name := range names {
+		index := tracker.getItemIndexByName(name)
+		if index < 0 {
+			log.Printf("warning: auto-tracker: unknown item '%s'", name)
+			continue
+		}
+
+		for tracker.items[index].belowItemState(states[name]) {
+			if !tracker.upgradeItemTagged(index, undoSourceAutoTracker, "") {
+				break
+			}
+
+			tracker.undoStack[len(tracker.undoStack)-1].IsChained = changed
+			changed = true
+		}
+	}
+
+	return changed
+}
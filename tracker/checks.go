@@ -0,0 +1,94 @@
+package tracker
+
+import (
+	"image"
+	"image/color"
+	"log"
+)
+
+// checkedColor dims the background of a checked hint.
+func checkedColor(clr color.RGBA) color.RGBA {
+	return color.RGBA{clr.R/3 + 0x60, clr.G/3 + 0x60, clr.B/3 + 0x60, clr.A}
+}
+
+// getHintAt returns the hint panel entry under the given point on the current
+// page.
+func (tracker *Tracker) getHintAt(x, y int) (drawableHintEntry, bool) {
+	p := image.Point{x, y}
+	if !p.In(tracker.cfg.Layout.HintTracker) {
+		return drawableHintEntry{}, false
+	}
+
+	entries := tracker.getDrawableHintList()
+	layout := tracker.getHintPanelLayout(len(entries))
+	start := min(tracker.hintPage, layout.pages-1) * layout.perPage()
+	end := min(len(entries), start+layout.perPage())
+
+	for k, v := range entries[start:end] {
+		if p.In(layout.entryRect(tracker.cfg.Layout.HintTracker.Min, k)) {
+			return v, true
+		}
+	}
+
+	return drawableHintEntry{}, false
+}
+
+// toggleHintChecked marks a hint as cleared or not in an undoable way.
+func (tracker *Tracker) toggleHintChecked(category string, index int) {
+	if !tracker.setHintChecked(category, index) {
+		return
+	}
+
+	tracker.appendEntryToUndoStack(undoStackEntry{
+		IsHint:       true,
+		IsCheck:      true,
+		HintCategory: category,
+		HintIndex:    index,
+	})
+}
+
+// setHintChecked toggles the checked state of a hint, returning false if there
+// is no such hint.
+func (tracker *Tracker) setHintChecked(category string, index int) bool {
+	hints := tracker.hints[category]
+	if index < 0 || index >= len(hints) {
+		log.Printf("warning: no hint %d in '%s' to check", index, category)
+		return false
+	}
+
+	hints[index].Checked = !hints[index].Checked
+	return true
+}
+
+// inputHintSelection moves the hint selection with the keypad arrows and
+// toggles the selected hint with the middle key.
+func (tracker *Tracker) inputHintSelection(a action) {
+	entries := tracker.getDrawableHintList()
+	if len(entries) == 0 {
+		tracker.input.reset()
+		return
+	}
+
+	layout := tracker.getHintPanelLayout(len(entries))
+	selected := tracker.input.selectedHint
+
+	switch a { //nolint:exhaustive
+	case actionTop:
+		selected--
+	case actionBottom:
+		selected++
+	case actionLeft:
+		selected -= layout.rows
+	case actionRight:
+		selected += layout.rows
+	case actionMiddle:
+		if selected < len(entries) {
+			tracker.toggleHintChecked(entries[selected].category, entries[selected].index)
+		}
+	default:
+		return
+	}
+
+	tracker.input.selectedHint = max(0, min(selected, len(entries)-1))
+	tracker.hintPage = tracker.input.selectedHint / layout.perPage()
+}
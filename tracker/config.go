@@ -4,18 +4,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
+	"ivan/autotracker"
 	"ivan/inputviewer"
+	"ivan/multiworld"
+	"ivan/theme"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type hintTrackerConfig struct {
-	AlwaysHints map[string]image.Point
+	Categories  []hintCategory
+	GoalTargets map[string]*image.Point // goal name to its sprite position, nil to display the name
+
+	// Display the first alias of a location instead of its full name.
+	ShortLocations bool
+
+	// Locations listed apart in the unknown regions panel.
+	DungeonLocations []string
 }
 
 type itemTrackerConfig struct {
 	DungeonInputMedallionOrder []string
 	DungeonInputDungeonKP      []string
+	DungeonModeInputExtraKP    []string // dungeons without a reward, for the vanilla/MQ input
 	ZoneItemMap                ZoneItemMap
 }
 
@@ -26,17 +38,34 @@ type Config struct {
 	HintTracker hintTrackerConfig
 	ItemTracker itemTrackerConfig
 
-	Items     []Item
-	Locations []string // regions and dungeons.
+	Checks          []string // item locations named by hints
+	Dungeons        []Dungeon
+	GossipStones    []gossipRegion
+	Entrances       entranceConfig
+	Items           []Item
+	Locations       []string            // regions and dungeons.
+	LocationAliases map[string][]string // location name to its abbreviations.
 
+	AutoTracker autotracker.Config
 	InputViewer inputviewer.Config
+	Multiworld  multiworld.Config
+	Theme       theme.Config
 	Layout      layout
+	Map         mapConfig
 }
 
 type layout struct {
 	ItemTracker image.Rectangle
 	Timer       image.Rectangle
 	HintTracker image.Rectangle
+	Map         image.Rectangle // optional, an empty rectangle disables the map
+	Dungeons    image.Rectangle // optional, an empty rectangle hides the panel
+	Entrances   image.Rectangle // optional, an empty rectangle hides the entrance list
+
+	UnknownRegions image.Rectangle // optional, an empty rectangle hides the panel
+	GossipStones   image.Rectangle // optional, an empty rectangle hides the panel
+	InputHistory   image.Rectangle // optional, an empty rectangle hides the panel
+	MultiworldLog  image.Rectangle // optional, an empty rectangle hides the panel
 }
 
 func (l layout) WindowSize() image.Point {
@@ -45,6 +74,13 @@ func (l layout) WindowSize() image.Point {
 		l.ItemTracker,
 		l.Timer,
 		l.HintTracker,
+		l.Map,
+		l.Dungeons,
+		l.Entrances,
+		l.UnknownRegions,
+		l.GossipStones,
+		l.InputHistory,
+		l.MultiworldLog,
 	} {
 		ret = ret.Union(v)
 	}
@@ -52,16 +88,50 @@ func (l layout) WindowSize() image.Point {
 	return ret.Size()
 }
 
+// aliasToLocation returns the location the given alias stands for or an empty
+// string if there is none.
+func (cfg Config) aliasToLocation(alias string) string {
+	alias = strings.TrimSpace(alias)
+	for location, aliases := range cfg.LocationAliases {
+		for _, v := range aliases {
+			if strings.EqualFold(v, alias) {
+				return location
+			}
+		}
+	}
+
+	return ""
+}
+
+// shortLocation returns the first alias of the given location or the location
+// itself if it has none.
+func (cfg Config) shortLocation(location string) string {
+	if aliases := cfg.LocationAliases[location]; len(aliases) > 0 {
+		return aliases[0]
+	}
+
+	return location
+}
+
 func NewConfigFromDir(dir string) (Config, error) {
 	var cfg Config
 	src := map[string]interface{}{
-		"binds.json":        &cfg.Binds,
-		"hint_tracker.json": &cfg.HintTracker,
-		"input_viewer.json": &cfg.InputViewer,
-		"item_tracker.json": &cfg.ItemTracker,
-		"items.json":        &cfg.Items,
-		"layout.json":       &cfg.Layout,
-		"locations.json":    &cfg.Locations,
+		"auto_tracker.json":     &cfg.AutoTracker,
+		"binds.json":            &cfg.Binds,
+		"checks.json":           &cfg.Checks,
+		"dungeons.json":         &cfg.Dungeons,
+		"entrances.json":        &cfg.Entrances,
+		"gossip_stones.json":    &cfg.GossipStones,
+		"hint_tracker.json":     &cfg.HintTracker,
+		"input_viewer.json":     &cfg.InputViewer,
+		"item_tracker.json":     &cfg.ItemTracker,
+		"items.json":            &cfg.Items,
+		"layout.json":           &cfg.Layout,
+		"location_aliases.json": &cfg.LocationAliases,
+		"locations.json":        &cfg.Locations,
+		"map.json":              &cfg.Map,
+		"multiworld.json":       &cfg.Multiworld,
+		"theme.json":            &cfg.Theme,
 	}
 
 	for name, dst := range src {
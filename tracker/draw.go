@@ -1,10 +1,9 @@
 package tracker
 
 import (
-	"fmt"
 	"image"
-	"image/color"
 	"strconv"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -45,9 +44,16 @@ func (tracker *Tracker) Draw(screen *ebiten.Image) {
 	drawState(true, tracker.sheetEnabled)
 
 	tracker.drawDungeons(screen)
+	tracker.drawDungeonPanel(screen)
+	tracker.drawSongDestinations(screen)
 	tracker.drawCapacities(screen)
 	tracker.drawInputState(screen)
 	tracker.drawHints(screen)
+	tracker.drawMap(screen)
+	tracker.drawEntrances(screen)
+	tracker.drawUnknownRegions(screen)
+	tracker.drawGossipStones(screen)
+	tracker.drawMultiworldLog(screen)
 }
 
 func (tracker *Tracker) drawActiveItemSlot(screen *ebiten.Image, slot int) {
@@ -84,14 +90,16 @@ func (tracker *Tracker) drawActiveItemSlot(screen *ebiten.Image, slot int) {
 		screen,
 		float32(pos.X), float32(pos.Y),
 		float32(size.X), float32(size.Y),
-		color.RGBA{0xFF, 0xFF, 0xFF, 0x50},
+		tracker.theme.Highlight,
 		false,
 	)
 }
 
 func (tracker *Tracker) drawDungeons(screen *ebiten.Image) {
-	var op = &text.DrawOptions{}
-	op.ColorScale.ScaleWithColor(color.White)
+	var (
+		op         = &text.DrawOptions{}
+		duplicates = tracker.getDuplicateDungeons()
+	)
 
 	for k := range tracker.items {
 		if !tracker.items[k].IsMedallion {
@@ -100,15 +108,22 @@ func (tracker *Tracker) drawDungeons(screen *ebiten.Image) {
 
 		rect := tracker.items[k].Rect()
 
+		op.ColorScale.Reset()
+		if _, ok := duplicates[tracker.items[k].DungeonIndex]; ok {
+			op.ColorScale.ScaleWithColor(tracker.theme.DungeonDuplicate)
+		} else {
+			op.ColorScale.ScaleWithColor(tracker.dungeonModeColor(tracker.getDungeonMode(tracker.items[k].DungeonIndex)))
+		}
+
 		op.GeoM.Reset()
-		op.GeoM.Translate(float64(rect.Min.X), float64(rect.Max.Y-trackerSmallFontSize))
-		text.Draw(screen, tracker.items[k].DungeonText(), tracker.fontSmall, op)
+		op.GeoM.Translate(float64(rect.Min.X), float64(rect.Max.Y-tracker.smallFontSize()))
+		text.Draw(screen, tracker.getDungeonText(tracker.items[k]), tracker.fontSmall, op)
 	}
 }
 
 func (tracker *Tracker) drawCapacities(screen *ebiten.Image) {
 	var op = &text.DrawOptions{}
-	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
 
 	for k := range tracker.items {
 		var count int
@@ -140,7 +155,7 @@ func (tracker *Tracker) drawCapacities(screen *ebiten.Image) {
 
 		str := strconv.Itoa(count)
 		op.GeoM.Reset()
-		op.GeoM.Translate(float64(x), float64(y-trackerFontSize))
+		op.GeoM.Translate(float64(x), float64(y)-tracker.theme.Font.Size)
 		text.Draw(screen, str, tracker.font, op)
 	}
 }
@@ -150,7 +165,7 @@ func (tracker *Tracker) drawInputState(screen *ebiten.Image) {
 
 	switch tracker.input.state {
 	case inputStateIdle:
-		// NOP
+		str = tracker.message
 	case inputStateItemInput, inputStateItemKPZoneInput:
 		if tracker.input.downgradeNextItem {
 			str = "-"
@@ -160,24 +175,51 @@ func (tracker *Tracker) drawInputState(screen *ebiten.Image) {
 
 	case inputStateTextInput:
 		str = "> " + string(tracker.input.buf)
-		switch tracker.input.textInputFor { //nolint:exhaustive
-		case hintTypeWOTH, hintTypeBarren:
-			if match := tracker.matchLocation(string(tracker.input.buf)); match != "" {
-				str += " (" + match + ")"
-			}
-		case hintTypeAlways:
-			index, _ := tracker.parseAlways(string(tracker.input.buf))
-			if index > -1 {
-				str += fmt.Sprintf(` (%s)`, tracker.getAlwaysLocations()[index])
+		if match := tracker.getHintPreview(tracker.getInputCategory(), string(tracker.input.buf)); match != "" {
+			str += " (" + match + ")"
+		}
+
+	case inputStateDungeonModeInput:
+		str = "vanilla/MQ dungeon"
+		if tracker.input.dungeonModeExtra {
+			str = "vanilla/MQ other dungeon"
+		}
+		if tracker.input.downgradeNextItem {
+			str += " (back)"
+		}
+
+	case inputStateSongInput:
+		str = "warp> " + string(tracker.input.buf)
+		if index, location, _ := tracker.parseSongDestination(string(tracker.input.buf)); index >= 0 {
+			str += " (" + tracker.items[index].Name
+			if location != "" {
+				str += " = " + tracker.cfg.shortLocation(location)
 			}
+			str += ")"
 		}
 
+	case inputStateEntranceInput:
+		str = tracker.getEntranceInputText()
+
+	case inputStateGossipInput:
+		str = tracker.getGossipInputText()
+
+	case inputStateHintSelection:
+		str = "check hint (8/2/4/6 move, 5 toggle)"
+
+	case inputStateAltarInput:
+		str = "altar> " + tailText(string(tracker.input.buf), altarInputDisplayLength)
+
 	case inputStateDungeonInput:
 		str = "dungeon for: "
 		idx := tracker.getItemIndexByName(
 			tracker.cfg.ItemTracker.DungeonInputMedallionOrder[tracker.input.curMedallion],
 		)
 		str += tracker.items[idx].Name
+		if tracker.message != "" {
+			str = tracker.message
+		}
+		tracker.drawUnassignedDungeons(screen)
 
 		// Highlight corresponding medallion.
 		rect := tracker.items[idx].Rect()
@@ -186,7 +228,7 @@ func (tracker *Tracker) drawInputState(screen *ebiten.Image) {
 			screen,
 			float32(rect.Min.X), float32(rect.Min.Y),
 			float32(size.X), float32(size.Y),
-			color.RGBA{0xFF, 0xFF, 0xFF, 0x50},
+			tracker.theme.Highlight,
 			false,
 		)
 	}
@@ -200,114 +242,49 @@ func (tracker *Tracker) drawInputState(screen *ebiten.Image) {
 	)
 
 	op := &text.DrawOptions{}
-	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
 	op.GeoM.Reset()
-	op.GeoM.Translate(float64(pos.X), float64(pos.Y)-trackerSmallFontSize)
+	op.GeoM.Translate(float64(pos.X), float64(pos.Y-tracker.smallFontSize()))
 	text.Draw(screen, str, tracker.fontSmall, op)
 }
 
-func (tracker *Tracker) drawHints(screen *ebiten.Image) {
-	var (
-		margins     = image.Point{3, 15}
-		iconOffsetY = 1
-		size        = tracker.cfg.Layout.HintTracker.Size()
-		pos         = tracker.cfg.Layout.HintTracker.Min.Add(margins)
-		lineHeight  = size.Y / maxHintsPerRow
-		op          = ebiten.DrawImageOptions{}
-		textOp      = &text.DrawOptions{}
-	)
-	textOp.ColorScale.ScaleWithColor(color.Black)
-
-	for k, v := range tracker.getDrawableHintList() {
-		if k > 0 && k%maxHintsPerRow == 0 {
-			pos = pos.Add(image.Point{size.X / 2, -maxHintsPerRow * lineHeight})
-		}
-
-		vector.DrawFilledRect(
-			screen,
-			float32(pos.X-margins.X), float32(pos.Y-margins.Y),
-			float32(size.X/2),
-			float32(size.Y/maxHintsPerRow),
-			v.bgColor,
-			false,
-		)
-
-		textOp.GeoM.Reset()
-		textOp.GeoM.Translate(float64(pos.X), float64(pos.Y)-trackerSmallFontSize)
-
-		if v.gfx != nil {
-			op.GeoM.Reset()
-			op.GeoM.Translate(
-				float64(pos.X),
-				float64(pos.Y-margins.Y+iconOffsetY),
-			)
-
-			screen.DrawImage(tracker.sheetEnabled.SubImage(*v.gfx).(*ebiten.Image), &op)
-			textOp.GeoM.Translate(25, 0)
-		}
-
-		text.Draw(screen, v.text, tracker.fontSmall, textOp)
-
-		pos.Y += lineHeight
+// drawUnassignedDungeons lists the dungeons without a stone or medallion above
+// the input state.
+func (tracker *Tracker) drawUnassignedDungeons(screen *ebiten.Image) {
+	unassigned := tracker.getUnassignedDungeons()
+	if len(unassigned) == 0 {
+		return
 	}
-}
 
-type drawableHintEntry struct {
-	text    string
-	gfx     *image.Rectangle
-	bgColor color.RGBA
-}
-
-const (
-	maxHintsPerRow = 10
-)
-
-func (tracker *Tracker) getDrawableHintList() []drawableHintEntry {
-	entries := make(
-		[]drawableHintEntry, 0,
-		len(tracker.woths)+
-			len(tracker.barrens)+
-			len(tracker.sometimes)+
-			len(tracker.always)+
-			len(tracker.goals),
+	str := "left: " + strings.Join(unassigned, " ")
+	_, h := text.Measure(str, tracker.fontSmall, 0)
+	pos := tracker.cfg.Layout.ItemTracker.Min.Add(
+		image.Point{0, 15 + 9*gridSize - 2*tracker.smallFontSize() - 4},
 	)
 
-	for _, v := range tracker.woths {
-		entries = append(entries, drawableHintEntry{text: v, bgColor: color.RGBA{212, 234, 107, 0xFF}})
-	}
-
-	for _, v := range tracker.goals {
-		entries = append(entries, drawableHintEntry{text: v, bgColor: color.RGBA{212, 234, 107, 0xFF}})
-	}
-
-	for _, v := range tracker.barrens {
-		entries = append(entries, drawableHintEntry{text: v, bgColor: color.RGBA{255, 109, 109, 0xFF}})
-	}
-
-	for _, v := range tracker.sometimes {
-		entries = append(entries, drawableHintEntry{text: v, bgColor: color.RGBA{180, 198, 231, 0xFF}})
-	}
+	vector.DrawFilledRect(
+		screen,
+		float32(pos.X), float32(pos.Y),
+		float32(tracker.cfg.Layout.ItemTracker.Dx()), float32(h)+4,
+		tracker.theme.Overlay,
+		false,
+	)
 
-	for k, v := range tracker.always {
-		name := tracker.getAlwaysLocations()[k]
-		if v == "" {
-			continue
-		}
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
+	op.GeoM.Translate(float64(pos.X+10), float64(pos.Y+2))
+	text.Draw(screen, str, tracker.fontSmall, op)
+}
 
-		entry := drawableHintEntry{
-			text:    v,
-			bgColor: color.RGBA{255, 230, 153, 0xFF},
-			gfx: &image.Rectangle{
-				tracker.cfg.HintTracker.AlwaysHints[name],
-				image.Point{
-					tracker.cfg.HintTracker.AlwaysHints[name].X + itemSpriteWidth,
-					tracker.cfg.HintTracker.AlwaysHints[name].Y + itemSpriteHeight,
-				},
-			},
-		}
+const altarInputDisplayLength = 35
 
-		entries = append(entries, entry)
+// tailText returns the last n runes of str, prefixed by an ellipsis if the
+// text was cut.
+func tailText(str string, n int) string {
+	runes := []rune(str)
+	if len(runes) <= n {
+		return str
 	}
 
-	return entries
+	return hintEllipsis + string(runes[len(runes)-n:])
 }
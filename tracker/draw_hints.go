@@ -0,0 +1,342 @@
+package tracker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"ivan/theme"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	maxHintsPerRow  = 10 // rows in a column at the theme font size
+	hintColumns     = 2
+	hintMinFontSize = 9
+	hintLinePadding = 8 // vertical space around the text of a row
+	hintIconWidth   = 25
+	hintEllipsis    = "…"
+)
+
+const hintIssuePrefix = "! "
+
+// hintPanelLayout holds the dimensions of the hint panel for a given number of
+// entries.
+type hintPanelLayout struct {
+	fontSize    float64
+	rows        int // per column
+	lineHeight  int
+	columnWidth int
+	pages       int
+}
+
+func (l hintPanelLayout) perPage() int {
+	return l.rows * hintColumns
+}
+
+// entryRect returns the rectangle of the k-th entry of a page.
+func (l hintPanelLayout) entryRect(origin image.Point, k int) image.Rectangle {
+	return image.Rect(0, 0, l.columnWidth, l.lineHeight).Add(origin).Add(image.Point{
+		(k / l.rows) * l.columnWidth,
+		(k % l.rows) * l.lineHeight,
+	})
+}
+
+// getHintPanelLayout shrinks the font until all entries fit in the panel,
+// when the smallest font is not enough entries are split in pages.
+func (tracker *Tracker) getHintPanelLayout(count int) hintPanelLayout {
+	size := tracker.cfg.Layout.HintTracker.Size()
+	layout := hintPanelLayout{
+		columnWidth: size.X / hintColumns,
+		pages:       1,
+	}
+
+	largest := max(tracker.theme.SmallFont.Size, hintMinFontSize)
+	for fontSize := largest; fontSize >= hintMinFontSize; fontSize-- {
+		layout.fontSize = fontSize
+		layout.rows = max(1, size.Y/(int(fontSize)+hintLinePadding))
+		if fontSize == largest {
+			layout.rows = min(layout.rows, maxHintsPerRow)
+		}
+		layout.lineHeight = size.Y / layout.rows
+
+		if count <= layout.perPage() {
+			return layout
+		}
+	}
+
+	layout.pages = (count + layout.perPage() - 1) / layout.perPage()
+
+	return layout
+}
+
+// hintPageCount returns the number of pages needed to display all hints.
+func (tracker *Tracker) hintPageCount() int {
+	return tracker.getHintPanelLayout(len(tracker.getDrawableHintList())).pages
+}
+
+// ScrollHints moves the hint panel to the next or previous page, cycling around.
+func (tracker *Tracker) ScrollHints(next bool) {
+	pages := tracker.hintPageCount()
+	if next {
+		tracker.hintPage = (tracker.hintPage + 1) % pages
+		return
+	}
+
+	tracker.hintPage--
+	if tracker.hintPage < 0 {
+		tracker.hintPage = pages - 1
+	}
+}
+
+// Hover is called with the current cursor position so the hint panel can
+// display the full text of the entry under it.
+func (tracker *Tracker) Hover(x, y int) {
+	tracker.cursor = image.Point{x, y}
+}
+
+//nolint:funlen
+func (tracker *Tracker) drawHints(screen *ebiten.Image) {
+	var (
+		entries     = tracker.getDrawableHintList()
+		layout      = tracker.getHintPanelLayout(len(entries))
+		margins     = image.Point{3, 2}
+		iconOffsetY = 1
+		origin      = tracker.cfg.Layout.HintTracker.Min
+		face        = tracker.hintFace(layout.fontSize)
+		op          = ebiten.DrawImageOptions{}
+		textOp      = &text.DrawOptions{}
+		tooltip     string
+	)
+
+	page := min(tracker.hintPage, layout.pages-1)
+	start := page * layout.perPage()
+	end := min(len(entries), start+layout.perPage())
+
+	for k, v := range entries[start:end] {
+		rect := layout.entryRect(origin, k)
+
+		bgColor, textColor := v.bgColor, v.textColor
+		if v.checked {
+			bgColor, textColor = checkedColor(bgColor), tracker.theme.HintCheckedText
+		}
+
+		vector.DrawFilledRect(
+			screen,
+			float32(rect.Min.X), float32(rect.Min.Y),
+			float32(rect.Dx()), float32(rect.Dy()),
+			bgColor,
+			false,
+		)
+		tracker.drawHintIssue(screen, rect, v.issue)
+		if tracker.kbInputStateIs(inputStateHintSelection) && start+k == tracker.input.selectedHint {
+			vector.StrokeRect(
+				screen,
+				float32(rect.Min.X)+1, float32(rect.Min.Y)+1,
+				float32(rect.Dx())-2, float32(rect.Dy())-2,
+				2, tracker.theme.HintSelection, false,
+			)
+		}
+
+		pos := rect.Min.Add(margins)
+		textWidth := float64(rect.Dx() - 2*margins.X)
+		textOp.GeoM.Reset()
+		textOp.GeoM.Translate(float64(pos.X), float64(pos.Y))
+
+		for k, gfx := range v.gfx {
+			op.GeoM.Reset()
+			op.GeoM.Translate(float64(pos.X+k*hintIconWidth), float64(rect.Min.Y+iconOffsetY))
+			op.ColorScale.Reset()
+			if v.checked {
+				op.ColorScale.ScaleAlpha(0.5)
+			}
+
+			screen.DrawImage(tracker.sheetEnabled.SubImage(gfx).(*ebiten.Image), &op)
+			textOp.GeoM.Translate(hintIconWidth, 0)
+			textWidth -= hintIconWidth
+		}
+
+		full := v.text
+		if v.issue != hintIssueNone {
+			full = hintIssuePrefix + full
+		}
+
+		str := ellipsize(full, face, textWidth)
+		textOp.ColorScale.Reset()
+		textOp.ColorScale.ScaleWithColor(textColor)
+		text.Draw(screen, str, face, textOp)
+
+		if v.checked {
+			x, y := textOp.GeoM.Apply(0, layout.fontSize*0.6)
+			vector.StrokeLine(
+				screen,
+				float32(x), float32(y),
+				float32(x+text.Advance(str, face)), float32(y),
+				1, textColor, false,
+			)
+		}
+
+		if str != full && tracker.cursor.In(rect) {
+			tooltip = full
+		}
+	}
+
+	if layout.pages > 1 {
+		tracker.drawPageIndicator(screen, tracker.cfg.Layout.HintTracker, page, layout.pages)
+	}
+
+	if tooltip != "" {
+		tracker.drawTooltip(screen, tooltip, tracker.cfg.Layout.HintTracker)
+	}
+}
+
+// drawHintIssue outlines a hint that is duplicated or contradicts another.
+func (tracker *Tracker) drawHintIssue(screen *ebiten.Image, rect image.Rectangle, issue hintIssue) {
+	var clr theme.Color
+	switch issue {
+	case hintIssueNone:
+		return
+	case hintIssueDuplicate:
+		clr = tracker.theme.HintDuplicate
+	case hintIssueContradiction:
+		clr = tracker.theme.HintContradiction
+	}
+
+	vector.StrokeRect(
+		screen,
+		float32(rect.Min.X)+1, float32(rect.Min.Y)+1,
+		float32(rect.Dx())-2, float32(rect.Dy())-2,
+		2, clr, false,
+	)
+}
+
+func (tracker *Tracker) hintFace(size float64) text.Face {
+	if size == tracker.theme.SmallFont.Size {
+		return tracker.fontSmall
+	}
+
+	return &text.GoTextFace{
+		Source: tracker.theme.SmallFace().Source,
+		Size:   size,
+	}
+}
+
+// ellipsize shortens str so it fits in the given width using the given face.
+func ellipsize(str string, face text.Face, width float64) string {
+	if text.Advance(str, face) <= width {
+		return str
+	}
+
+	runes := []rune(str)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		ret := strings.TrimRight(string(runes), " ") + hintEllipsis
+		if text.Advance(ret, face) <= width {
+			return ret
+		}
+	}
+
+	return hintEllipsis
+}
+
+func (tracker *Tracker) drawPageIndicator(screen *ebiten.Image, rect image.Rectangle, page, pages int) {
+	str := fmt.Sprintf("%d/%d", page+1, pages)
+	w, h := text.Measure(str, tracker.fontSmall, 0)
+
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
+	op.GeoM.Translate(float64(rect.Max.X)-w-2, float64(rect.Max.Y)-h)
+
+	vector.DrawFilledRect(
+		screen,
+		float32(rect.Max.X)-float32(w)-4, float32(rect.Max.Y)-float32(h),
+		float32(w)+4, float32(h),
+		tracker.theme.Overlay,
+		false,
+	)
+	text.Draw(screen, str, tracker.fontSmall, op)
+}
+
+// drawTooltip draws the given text in a box above the cursor, kept inside the
+// given bounds horizontally.
+func (tracker *Tracker) drawTooltip(screen *ebiten.Image, str string, bounds image.Rectangle) {
+	const padding = 3
+
+	w, h := text.Measure(str, tracker.fontSmall, 0)
+	box := image.Rect(0, 0, int(w)+2*padding, int(h)+2*padding)
+	pos := tracker.cursor.Sub(image.Point{box.Dx() / 2, box.Dy() + padding})
+
+	pos.X = max(bounds.Min.X, min(pos.X, bounds.Max.X-box.Dx()))
+	pos.Y = max(0, pos.Y)
+	box = box.Add(pos)
+
+	vector.DrawFilledRect(
+		screen,
+		float32(box.Min.X), float32(box.Min.Y),
+		float32(box.Dx()), float32(box.Dy()),
+		tracker.theme.Tooltip,
+		false,
+	)
+
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
+	op.GeoM.Translate(float64(box.Min.X+padding), float64(box.Min.Y+padding))
+	text.Draw(screen, str, tracker.fontSmall, op)
+}
+
+type drawableHintEntry struct {
+	text      string
+	gfx       []image.Rectangle
+	bgColor   color.RGBA
+	textColor color.Color
+	issue     hintIssue
+	checked   bool
+
+	// Position of the hint in tracker.hints.
+	category string
+	index    int
+}
+
+func (tracker *Tracker) getDrawableHintList() []drawableHintEntry {
+	var (
+		entries []drawableHintEntry
+		issues  = tracker.validateHints()
+	)
+
+	for _, category := range tracker.cfg.HintTracker.Categories {
+		for _, index := range tracker.getCategoryHintsOrder(category) {
+			v := tracker.hints[category.Name][index]
+			entries = append(entries, drawableHintEntry{
+				text:      tracker.hintText(v),
+				gfx:       tracker.hintIcons(category, v),
+				bgColor:   tracker.theme.HintBackground(category.Name, category.color()),
+				textColor: tracker.theme.HintTextColor(category.Name),
+				issue:     issues.hintIssue(category, v),
+				checked:   v.Checked,
+				category:  category.Name,
+				index:     index,
+			})
+		}
+	}
+
+	return entries
+}
+
+// spriteRect returns the rectangle of the item sprite at the given position on
+// the spritesheet.
+func spriteRect(pos image.Point) *image.Rectangle {
+	return &image.Rectangle{pos, pos.Add(image.Point{itemSpriteWidth, itemSpriteHeight})}
+}
+
+// locationText returns the text to display for a location hint, honoring the
+// short locations setting.
+func (tracker *Tracker) locationText(location string) string {
+	if !tracker.cfg.HintTracker.ShortLocations {
+		return location
+	}
+
+	return tracker.cfg.shortLocation(location)
+}
@@ -0,0 +1,261 @@
+package tracker
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Dungeon is a place that can hold a stone or a medallion.
+type Dungeon struct {
+	Name  string // as used in DungeonInputDungeonKP
+	Short string // displayed under stones and medallions
+
+	// Words used in the Temple of Time altar text to name this dungeon.
+	AltarKeywords []string `json:",omitempty"`
+
+	// Dungeon has a Master Quest variant.
+	HasMQ bool `json:",omitempty"`
+
+	// Dungeon cannot hold a stone or medallion, eg. Ice Cavern, it is only
+	// listed for its Master Quest mode.
+	NoReward bool `json:",omitempty"`
+}
+
+type dungeonMode int
+
+const (
+	dungeonModeUnknown dungeonMode = iota
+	dungeonModeVanilla
+	dungeonModeMQ
+
+	dungeonModeCount
+)
+
+const dungeonLinePadding = 4
+
+func (tracker *Tracker) dungeonModeColor(mode dungeonMode) color.Color {
+	switch mode {
+	case dungeonModeVanilla:
+		return tracker.theme.DungeonVanilla
+	case dungeonModeMQ:
+		return tracker.theme.DungeonMQ
+	case dungeonModeUnknown, dungeonModeCount:
+	}
+
+	return tracker.theme.Text
+}
+
+// getDungeonIndex returns the DungeonIndex of the given dungeon name, 0 being
+// the absence of dungeon.
+func (tracker *Tracker) getDungeonIndex(name string) int {
+	for k, v := range tracker.cfg.Dungeons {
+		if v.Name == name {
+			return k + 1
+		}
+	}
+
+	return 0
+}
+
+// getDungeon returns the dungeon of a DungeonIndex.
+func (tracker *Tracker) getDungeon(index int) (Dungeon, bool) {
+	if index <= 0 || index > len(tracker.cfg.Dungeons) {
+		return Dungeon{}, false
+	}
+
+	return tracker.cfg.Dungeons[index-1], true
+}
+
+// getDungeonText returns the text to display under a stone or medallion.
+func (tracker *Tracker) getDungeonText(item Item) string {
+	dungeon, ok := tracker.getDungeon(item.DungeonIndex)
+	if !ok {
+		return ""
+	}
+
+	return dungeon.Short
+}
+
+func (tracker *Tracker) setDungeon(itemIndex int, dungeon string) {
+	tracker.items[itemIndex].DungeonIndex = tracker.getDungeonIndex(dungeon)
+}
+
+// cycleDungeon cycles the dungeon of a stone or medallion, skipping the
+// dungeons that cannot hold one.
+func (tracker *Tracker) cycleDungeon(itemIndex int, up bool) {
+	count := len(tracker.cfg.Dungeons) + 1
+	item := &tracker.items[itemIndex]
+
+	for range count {
+		if up {
+			item.DungeonIndex = (item.DungeonIndex + 1) % count
+		} else {
+			item.DungeonIndex = (item.DungeonIndex + count - 1) % count
+		}
+
+		if dungeon, ok := tracker.getDungeon(item.DungeonIndex); !ok || !dungeon.NoReward {
+			return
+		}
+	}
+}
+
+func (tracker *Tracker) getDungeonMode(index int) dungeonMode {
+	dungeon, ok := tracker.getDungeon(index)
+	if !ok {
+		return dungeonModeUnknown
+	}
+
+	return tracker.dungeonModes[dungeon.Name]
+}
+
+// cycleDungeonMode cycles a dungeon between unknown, vanilla and MQ.
+func (tracker *Tracker) cycleDungeonMode(index int, up bool) {
+	dungeon, ok := tracker.getDungeon(index)
+	if !ok || !dungeon.HasMQ {
+		return
+	}
+
+	if tracker.dungeonModes == nil {
+		tracker.dungeonModes = make(map[string]dungeonMode, len(tracker.cfg.Dungeons))
+	}
+
+	mode := tracker.dungeonModes[dungeon.Name]
+	if up {
+		mode = (mode + 1) % dungeonModeCount
+	} else {
+		mode = (mode + dungeonModeCount - 1) % dungeonModeCount
+	}
+
+	tracker.dungeonModes[dungeon.Name] = mode
+}
+
+// dungeonLabelRect returns the position of the dungeon text of a stone or
+// medallion relative to the background origin.
+func (tracker *Tracker) dungeonLabelRect(item Item) image.Rectangle {
+	rect := item.Rect()
+	rect.Min.Y = rect.Max.Y - tracker.smallFontSize()
+
+	return rect
+}
+
+// getDungeonLabelAt returns the DungeonIndex of the dungeon label under the
+// given point or 0.
+func (tracker *Tracker) getDungeonLabelAt(x, y int) int {
+	p := image.Point{x, y}
+	for _, v := range tracker.items {
+		if v.IsMedallion && v.DungeonIndex > 0 && p.In(tracker.dungeonLabelRect(v)) {
+			return v.DungeonIndex
+		}
+	}
+
+	return 0
+}
+
+// inputDungeonMode cycles the mode of the dungeon at the given KP zone, using
+// the same layout as the dungeon input, or DungeonModeInputExtraKP for the
+// dungeons without a reward.
+func (tracker *Tracker) inputDungeonMode(a action) {
+	layout := tracker.cfg.ItemTracker.DungeonInputDungeonKP
+	if tracker.input.dungeonModeExtra {
+		layout = tracker.cfg.ItemTracker.DungeonModeInputExtraKP
+	}
+
+	zone := actionToKPZone(a)
+	if zone <= 0 || zone > len(layout) || layout[zone-1] == "" {
+		tracker.input.reset()
+		return
+	}
+
+	tracker.cycleDungeonMode(tracker.getDungeonIndex(layout[zone-1]), !tracker.input.downgradeNextItem)
+}
+
+func (tracker *Tracker) dungeonsEnabled() bool {
+	return !tracker.cfg.Layout.Dungeons.Empty()
+}
+
+// getMQDungeonIndexes returns the DungeonIndex of the dungeons listed in the
+// dungeon panel, the ones with a Master Quest variant.
+func (tracker *Tracker) getMQDungeonIndexes() []int {
+	var ret []int
+	for k, v := range tracker.cfg.Dungeons {
+		if v.HasMQ {
+			ret = append(ret, k+1)
+		}
+	}
+
+	return ret
+}
+
+// dungeonPanelRects returns the position of each dungeon of the panel, filling
+// columns left to right.
+func (tracker *Tracker) dungeonPanelRects() []image.Rectangle {
+	var (
+		bounds     = tracker.cfg.Layout.Dungeons
+		count      = len(tracker.getMQDungeonIndexes())
+		lineHeight = tracker.smallFontSize() + dungeonLinePadding
+		rows       = max(1, bounds.Dy()/lineHeight)
+		columns    = max(1, (count+rows-1)/rows)
+		width      = bounds.Dx() / columns
+		ret        = make([]image.Rectangle, count)
+	)
+
+	for k := range ret {
+		origin := bounds.Min.Add(image.Point{(k / rows) * width, (k % rows) * lineHeight})
+		ret[k] = image.Rectangle{origin, origin.Add(image.Point{width, lineHeight})}
+	}
+
+	return ret
+}
+
+// getDungeonPanelAt returns the DungeonIndex of the dungeon of the panel under
+// the given point or 0.
+func (tracker *Tracker) getDungeonPanelAt(x, y int) int {
+	if !tracker.dungeonsEnabled() {
+		return 0
+	}
+
+	indexes := tracker.getMQDungeonIndexes()
+	for k, rect := range tracker.dungeonPanelRects() {
+		if (image.Point{x, y}).In(rect) {
+			return indexes[k]
+		}
+	}
+
+	return 0
+}
+
+// drawDungeonPanel lists every dungeon with a Master Quest variant in the
+// color of its mode, including the ones not holding a stone or medallion.
+func (tracker *Tracker) drawDungeonPanel(screen *ebiten.Image) {
+	if !tracker.dungeonsEnabled() {
+		return
+	}
+
+	bounds := tracker.cfg.Layout.Dungeons
+	vector.DrawFilledRect(
+		screen,
+		float32(bounds.Min.X), float32(bounds.Min.Y),
+		float32(bounds.Dx()), float32(bounds.Dy()),
+		tracker.theme.PanelBackground,
+		false,
+	)
+
+	indexes := tracker.getMQDungeonIndexes()
+	op := &text.DrawOptions{}
+	for k, rect := range tracker.dungeonPanelRects() {
+		if rect.Max.Y > bounds.Max.Y {
+			continue
+		}
+
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(rect.Min.X+2), float64(rect.Min.Y+dungeonLinePadding/2))
+		op.ColorScale.Reset()
+		op.ColorScale.ScaleWithColor(tracker.dungeonModeColor(tracker.getDungeonMode(indexes[k])))
+		str := tracker.getDungeonText(Item{DungeonIndex: indexes[k]})
+		text.Draw(screen, ellipsize(str, tracker.fontSmall, float64(rect.Dx()-4)), tracker.fontSmall, op)
+	}
+}
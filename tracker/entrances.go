@@ -0,0 +1,265 @@
+package tracker
+
+import (
+	"image"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+type entranceConfig struct {
+	// When false, mapping an entrance also maps its reverse direction.
+	Decoupled bool
+	Entrances []entrance
+}
+
+// entrance is a shuffled transition named "From -> To" as in the randomizer
+// spoiler log.
+type entrance struct {
+	Name    string
+	Reverse string `json:",omitempty"` // empty for one-way entrances
+}
+
+const (
+	entranceSeparator   = " -> "
+	entranceLinePadding = 4
+)
+
+// getEntranceNames returns all known entrances, both directions included.
+func (tracker *Tracker) getEntranceNames() []string {
+	ret := make([]string, 0, 2*len(tracker.cfg.Entrances.Entrances))
+	for _, v := range tracker.cfg.Entrances.Entrances {
+		ret = append(ret, v.Name)
+		if v.Reverse != "" {
+			ret = append(ret, v.Reverse)
+		}
+	}
+
+	return ret
+}
+
+// getReverseEntrance returns the other direction of an entrance or an empty
+// string if it has none.
+func (tracker *Tracker) getReverseEntrance(name string) string {
+	for _, v := range tracker.cfg.Entrances.Entrances {
+		switch name {
+		case v.Name:
+			return v.Reverse
+		case v.Reverse:
+			return v.Name
+		}
+	}
+
+	return ""
+}
+
+func (tracker *Tracker) matchEntrance(str string) string {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return ""
+	}
+
+	matches := fuzzy.RankFindFold(str, tracker.getEntranceNames())
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Sort(matches)
+
+	return matches[0].Target
+}
+
+// entranceTarget returns the area an entrance leads to in the vanilla game.
+func entranceTarget(name string) string {
+	if i := strings.LastIndex(name, entranceSeparator); i >= 0 {
+		return name[i+len(entranceSeparator):]
+	}
+
+	return name
+}
+
+// submitEntranceInput reads the source entrance then its destination, an
+// empty destination removes the mapping of the source.
+func (tracker *Tracker) submitEntranceInput() {
+	match := tracker.matchEntrance(string(tracker.input.buf))
+
+	if tracker.input.entranceSource == "" {
+		if match == "" {
+			tracker.input.reset()
+			return
+		}
+
+		tracker.input.entranceSource = match
+		tracker.input.buf = tracker.input.buf[:0]
+		return
+	}
+
+	defer tracker.input.reset()
+	if match == "" && len(tracker.input.buf) > 0 {
+		return
+	}
+
+	tracker.mapEntrance(tracker.input.entranceSource, match)
+}
+
+// mapEntrance sets where an entrance leads in an undoable way, the reverse
+// direction is mapped in the same undo step unless entrances are decoupled.
+// The reverse of a replaced or removed mapping is removed as well.
+func (tracker *Tracker) mapEntrance(source, destination string) {
+	previous := tracker.entrances[source]
+	chained := tracker.setEntranceDestinationUndoable(source, destination, false)
+
+	if tracker.cfg.Entrances.Decoupled {
+		return
+	}
+
+	reverseDestination := tracker.getReverseEntrance(source)
+	if reverseDestination == "" {
+		return
+	}
+
+	if previous != "" && previous != destination {
+		reverseSource := tracker.getReverseEntrance(previous)
+		if reverseSource != "" && tracker.entrances[reverseSource] == reverseDestination {
+			chained = tracker.setEntranceDestinationUndoable(reverseSource, "", chained) || chained
+		}
+	}
+
+	if destination == "" {
+		return
+	}
+
+	reverseSource := tracker.getReverseEntrance(destination)
+	if reverseSource == "" {
+		return
+	}
+
+	tracker.setEntranceDestinationUndoable(reverseSource, reverseDestination, chained)
+}
+
+// setEntranceDestinationUndoable returns true if an undo entry was added.
+func (tracker *Tracker) setEntranceDestinationUndoable(source, destination string, chained bool) bool {
+	previous := tracker.entrances[source]
+	if previous == destination {
+		return false
+	}
+
+	tracker.setEntranceDestination(source, destination)
+	tracker.appendEntryToUndoStack(undoStackEntry{
+		IsEntrance:          true,
+		IsChained:           chained,
+		EntranceSource:      source,
+		Destination:         destination,
+		PreviousDestination: previous,
+	})
+
+	return true
+}
+
+func (tracker *Tracker) setEntranceDestination(source, destination string) {
+	if destination == "" {
+		delete(tracker.entrances, source)
+		return
+	}
+
+	if tracker.entrances == nil {
+		tracker.entrances = make(map[string]string)
+	}
+
+	tracker.entrances[source] = destination
+}
+
+// getEntranceInputText returns the input line of an entrance input.
+func (tracker *Tracker) getEntranceInputText() string {
+	var str string
+	if tracker.input.entranceSource == "" {
+		str = "entrance> "
+	} else {
+		str = tracker.input.entranceSource + " = "
+	}
+	str += string(tracker.input.buf)
+
+	if match := tracker.matchEntrance(string(tracker.input.buf)); match != "" {
+		str += " (" + match + ")"
+	}
+
+	return str
+}
+
+func (tracker *Tracker) entrancesEnabled() bool {
+	return !tracker.cfg.Layout.Entrances.Empty()
+}
+
+// getDrawableEntrances returns the mapped entrances in config order, filtered
+// by the text being typed in an entrance input.
+func (tracker *Tracker) getDrawableEntrances() []string {
+	var filter string
+	if tracker.kbInputStateIs(inputStateEntranceInput) {
+		filter = strings.TrimSpace(string(tracker.input.buf))
+	}
+
+	var ret []string
+	for _, source := range tracker.getEntranceNames() {
+		destination, ok := tracker.entrances[source]
+		if !ok {
+			continue
+		}
+
+		str := source + " = " + entranceTarget(destination)
+		if filter != "" && !fuzzy.MatchFold(filter, str) {
+			continue
+		}
+
+		ret = append(ret, str)
+	}
+
+	return ret
+}
+
+func (tracker *Tracker) entranceRows() int {
+	return max(1, tracker.cfg.Layout.Entrances.Dy()/(tracker.smallFontSize()+entranceLinePadding))
+}
+
+// ScrollEntrances moves the entrance list by one line.
+func (tracker *Tracker) ScrollEntrances(down bool) {
+	if down {
+		tracker.entranceScroll++
+	} else {
+		tracker.entranceScroll--
+	}
+
+	count := len(tracker.getDrawableEntrances())
+	tracker.entranceScroll = max(0, min(tracker.entranceScroll, count-tracker.entranceRows()))
+}
+
+func (tracker *Tracker) drawEntrances(screen *ebiten.Image) {
+	if !tracker.entrancesEnabled() {
+		return
+	}
+
+	bounds := tracker.cfg.Layout.Entrances
+	vector.DrawFilledRect(
+		screen,
+		float32(bounds.Min.X), float32(bounds.Min.Y),
+		float32(bounds.Dx()), float32(bounds.Dy()),
+		tracker.theme.PanelBackground,
+		false,
+	)
+
+	list := tracker.getDrawableEntrances()
+	start := max(0, min(tracker.entranceScroll, len(list)-tracker.entranceRows()))
+	list = list[start:min(len(list), start+tracker.entranceRows())]
+
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
+	for k, v := range list {
+		pos := bounds.Min.Add(image.Point{2, entranceLinePadding/2 + k*(tracker.smallFontSize()+entranceLinePadding)})
+
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(pos.X), float64(pos.Y))
+		text.Draw(screen, ellipsize(v, tracker.fontSmall, float64(bounds.Dx()-4)), tracker.fontSmall, op)
+	}
+}
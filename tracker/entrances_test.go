@@ -0,0 +1,60 @@
+package tracker //nolint:testpackage // the entrance mapping under test is unexported.
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMapEntranceCoupled(t *testing.T) {
+	tracker := newTestTracker(t)
+	tracker.cfg.Entrances.Decoupled = false
+
+	tracker.mapEntrance("Kokiri Forest -> Lost Woods", "Hyrule Field -> Lake Hylia")
+	expectEntrances(t, tracker, map[string]string{
+		"Kokiri Forest -> Lost Woods": "Hyrule Field -> Lake Hylia",
+		"Lake Hylia -> Hyrule Field":  "Lost Woods -> Kokiri Forest",
+	})
+
+	// Remapping clears the reverse of the previous destination.
+	tracker.mapEntrance("Kokiri Forest -> Lost Woods", "Market -> Castle Grounds")
+	expectEntrances(t, tracker, map[string]string{
+		"Kokiri Forest -> Lost Woods": "Market -> Castle Grounds",
+		"Castle Grounds -> Market":    "Lost Woods -> Kokiri Forest",
+	})
+
+	// A reverse mapped to something else is left alone.
+	tracker.entrances["Castle Grounds -> Market"] = "Zoras Domain -> Lake Hylia"
+	tracker.mapEntrance("Kokiri Forest -> Lost Woods", "")
+	expectEntrances(t, tracker, map[string]string{
+		"Castle Grounds -> Market": "Zoras Domain -> Lake Hylia",
+	})
+
+	// Undoing the chain restores the previous mappings.
+	tracker.entrances = nil
+	tracker.undoStack = nil
+	tracker.mapEntrance("Kokiri Forest -> Lost Woods", "Hyrule Field -> Lake Hylia")
+	tracker.mapEntrance("Kokiri Forest -> Lost Woods", "Market -> Castle Grounds")
+	tracker.undo()
+	expectEntrances(t, tracker, map[string]string{
+		"Kokiri Forest -> Lost Woods": "Hyrule Field -> Lake Hylia",
+		"Lake Hylia -> Hyrule Field":  "Lost Woods -> Kokiri Forest",
+	})
+}
+
+func TestMapEntranceDecoupled(t *testing.T) {
+	tracker := newTestTracker(t)
+	tracker.cfg.Entrances.Decoupled = true
+
+	tracker.mapEntrance("Kokiri Forest -> Lost Woods", "Hyrule Field -> Lake Hylia")
+	expectEntrances(t, tracker, map[string]string{
+		"Kokiri Forest -> Lost Woods": "Hyrule Field -> Lake Hylia",
+	})
+}
+
+func expectEntrances(t *testing.T, tracker *Tracker, expected map[string]string) {
+	t.Helper()
+
+	if !maps.Equal(tracker.entrances, expected) {
+		t.Errorf("expected %v, got %v", expected, tracker.entrances)
+	}
+}
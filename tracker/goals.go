@@ -0,0 +1,83 @@
+package tracker
+
+import (
+	"image"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// goalSeparator separates the location from the target in a goal hint input,
+// a comma can also be used. If none is present the location is the first word.
+const goalSeparator = "="
+
+// parseGoal reads a goal hint from user input, the location is fuzzy-matched
+// and the target is chosen from the configured goal targets.
+// Unmatched locations keep the input as freeform text.
+func (tracker *Tracker) parseGoal(str string) hint {
+	str = strings.TrimSpace(str)
+
+	var location, target string
+	if i := strings.IndexAny(str, goalSeparator+","); i >= 0 {
+		location, target = str[:i], str[i+1:]
+	} else {
+		parts := strings.SplitN(str, " ", 2)
+		location = parts[0]
+		if len(parts) > 1 {
+			target = parts[1]
+		}
+	}
+
+	location = tracker.matchLocation(strings.TrimSpace(location))
+	if location == "" {
+		return hint{Text: str}
+	}
+
+	return hint{
+		Location: location,
+		Target:   tracker.matchGoalTarget(target),
+	}
+}
+
+func (tracker *Tracker) getGoalTargets() []string {
+	ret := make([]string, 0, len(tracker.cfg.HintTracker.GoalTargets))
+	for k := range tracker.cfg.HintTracker.GoalTargets {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func (tracker *Tracker) matchGoalTarget(str string) string {
+	str = strings.TrimSpace(str)
+	for _, prefix := range []string{"path to ", "path of ", "to ", "of "} {
+		if len(str) >= len(prefix) && strings.EqualFold(str[:len(prefix)], prefix) {
+			str = strings.TrimSpace(str[len(prefix):])
+		}
+	}
+
+	if str == "" {
+		return ""
+	}
+
+	matches := fuzzy.RankFindFold(str, tracker.getGoalTargets())
+	if len(matches) == 0 {
+		return ""
+	}
+
+	sort.Sort(matches)
+	return matches[0].Target
+}
+
+// goalIcon returns the sprite of the goal target on the items spritesheet or
+// nil if the target has none.
+func (tracker *Tracker) goalIcon(goal hint) *image.Rectangle {
+	pos := tracker.cfg.HintTracker.GoalTargets[goal.Target]
+	if pos == nil {
+		return nil
+	}
+
+	return spriteRect(*pos)
+}
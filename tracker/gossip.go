@@ -0,0 +1,254 @@
+package tracker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// gossipRegion groups the gossip stones of a region, as set in
+// gossip_stones.json.
+type gossipRegion struct {
+	Region string
+	Stones []string
+}
+
+// gossipStone is the state of a stone that was read.
+type gossipStone struct {
+	Read bool
+
+	// Hint given by the stone, if it was linked.
+	HintCategory string `json:",omitempty"`
+	Hint         *hint  `json:",omitempty"`
+}
+
+const gossipLinePadding = 4
+
+func (tracker *Tracker) getGossipStoneNames() []string {
+	var ret []string
+	for _, v := range tracker.cfg.GossipStones {
+		ret = append(ret, v.Stones...)
+	}
+
+	return ret
+}
+
+func (tracker *Tracker) matchGossipStone(str string) string {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return ""
+	}
+
+	matches := fuzzy.RankFindFold(str, tracker.getGossipStoneNames())
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Sort(matches)
+
+	return matches[0].Target
+}
+
+// submitGossipInput toggles the read state of a stone, a stone marked as read
+// is linked to the next hint entered.
+func (tracker *Tracker) submitGossipInput() {
+	defer tracker.input.reset()
+
+	name := tracker.matchGossipStone(string(tracker.input.buf))
+	if name == "" {
+		return
+	}
+
+	stone := tracker.stones[name]
+	stone.Read = !stone.Read
+	if !stone.Read {
+		stone.HintCategory, stone.Hint = "", nil
+	}
+	tracker.setGossipStoneUndoable(name, stone, false)
+
+	tracker.pendingStone = ""
+	if stone.Read {
+		tracker.pendingStone, tracker.pendingUndoLen = name, len(tracker.undoStack)
+		tracker.setMessage("read " + name + ", next hint is linked")
+	}
+}
+
+// linkPendingStone links the stone marked as read to the given hint, in the
+// same undo step as the hint. Only a hint typed right after reading the stone
+// is linked, the hint being the only undo entry added since.
+func (tracker *Tracker) linkPendingStone(category string, h hint) {
+	pending := tracker.pendingStone
+	tracker.pendingStone = ""
+	if pending == "" || len(tracker.undoStack) != tracker.pendingUndoLen+1 {
+		return
+	}
+
+	stone := tracker.stones[pending]
+	stone.HintCategory, stone.Hint = category, &h
+	tracker.setGossipStoneUndoable(pending, stone, true)
+}
+
+func (tracker *Tracker) setGossipStoneUndoable(name string, stone gossipStone, chained bool) {
+	var previous *gossipStone
+	if v, ok := tracker.stones[name]; ok {
+		previous = &v
+	}
+
+	tracker.setGossipStone(name, &stone)
+	tracker.appendEntryToUndoStack(undoStackEntry{
+		IsStone:       true,
+		IsChained:     chained,
+		StoneName:     name,
+		Stone:         &stone,
+		PreviousStone: previous,
+	})
+}
+
+// setGossipStone sets the state of a stone, nil being unread.
+func (tracker *Tracker) setGossipStone(name string, stone *gossipStone) {
+	if stone == nil || !stone.Read {
+		delete(tracker.stones, name)
+		return
+	}
+
+	if tracker.stones == nil {
+		tracker.stones = make(map[string]gossipStone)
+	}
+
+	tracker.stones[name] = *stone
+}
+
+// getGossipInputText returns the input line of a gossip stone input, with the
+// hint given by the matched stone if it was read.
+func (tracker *Tracker) getGossipInputText() string {
+	str := "stone> " + string(tracker.input.buf)
+
+	name := tracker.matchGossipStone(string(tracker.input.buf))
+	if name == "" {
+		return str
+	}
+
+	str += " (" + name
+	if stone, ok := tracker.stones[name]; ok {
+		str += ", read"
+		if stone.Hint != nil {
+			str += ": " + tracker.hintText(*stone.Hint)
+		}
+	}
+
+	return str + ")"
+}
+
+// getGossipCount returns the number of read stones and stones of a region.
+func (tracker *Tracker) getGossipCount(region gossipRegion) (int, int) {
+	var read int
+	for _, v := range region.Stones {
+		if tracker.stones[v].Read {
+			read++
+		}
+	}
+
+	return read, len(region.Stones)
+}
+
+func (tracker *Tracker) gossipStonesEnabled() bool {
+	return !tracker.cfg.Layout.GossipStones.Empty()
+}
+
+// gossipLine is a line of the gossip stone panel.
+type gossipLine struct {
+	text   string
+	indent int
+	clr    color.Color
+}
+
+// getGossipLines returns the read count of every region followed by its
+// unread stones.
+func (tracker *Tracker) getGossipLines() []gossipLine {
+	var ret []gossipLine
+	for _, region := range tracker.cfg.GossipStones {
+		read, total := tracker.getGossipCount(region)
+		str := fmt.Sprintf("%s %d/%d", tracker.cfg.shortLocation(region.Region), read, total)
+		if read == total {
+			ret = append(ret, gossipLine{str, 0, tracker.theme.Dimmed})
+			continue
+		}
+
+		ret = append(ret, gossipLine{str, 0, tracker.theme.PanelHeader})
+		for _, v := range region.Stones {
+			if !tracker.stones[v].Read {
+				ret = append(ret, gossipLine{v, 8, tracker.theme.Text})
+			}
+		}
+	}
+
+	return ret
+}
+
+func (tracker *Tracker) gossipRows() int {
+	return max(1, tracker.cfg.Layout.GossipStones.Dy()/(tracker.smallFontSize()+gossipLinePadding))
+}
+
+func (tracker *Tracker) gossipPageCount() int {
+	return max(1, (len(tracker.getGossipLines())+tracker.gossipRows()-1)/tracker.gossipRows())
+}
+
+// ScrollGossipStones moves the gossip stone panel to the next or previous
+// page, cycling around.
+func (tracker *Tracker) ScrollGossipStones(next bool) {
+	pages := tracker.gossipPageCount()
+	if next {
+		tracker.gossipPage = (tracker.gossipPage + 1) % pages
+		return
+	}
+
+	tracker.gossipPage--
+	if tracker.gossipPage < 0 {
+		tracker.gossipPage = pages - 1
+	}
+}
+
+// drawGossipStones draws the current page of the gossip stone lines.
+func (tracker *Tracker) drawGossipStones(screen *ebiten.Image) {
+	if !tracker.gossipStonesEnabled() {
+		return
+	}
+
+	bounds := tracker.cfg.Layout.GossipStones
+	vector.DrawFilledRect(
+		screen,
+		float32(bounds.Min.X), float32(bounds.Min.Y),
+		float32(bounds.Dx()), float32(bounds.Dy()),
+		tracker.theme.PanelBackground,
+		false,
+	)
+
+	var (
+		lines      = tracker.getGossipLines()
+		rows       = tracker.gossipRows()
+		pages      = tracker.gossipPageCount()
+		page       = min(tracker.gossipPage, pages-1)
+		lineHeight = tracker.smallFontSize() + gossipLinePadding
+		pos        = bounds.Min.Add(image.Point{2, gossipLinePadding / 2})
+		op         = &text.DrawOptions{}
+	)
+
+	for _, v := range lines[page*rows : min(len(lines), (page+1)*rows)] {
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(pos.X+v.indent), float64(pos.Y))
+		op.ColorScale.Reset()
+		op.ColorScale.ScaleWithColor(v.clr)
+		text.Draw(screen, ellipsize(v.text, tracker.fontSmall, float64(bounds.Dx()-v.indent-4)), tracker.fontSmall, op)
+		pos.Y += lineHeight
+	}
+
+	if pages > 1 {
+		tracker.drawPageIndicator(screen, bounds, page, pages)
+	}
+}
@@ -1,114 +1,256 @@
 package tracker
 
 import (
+	"encoding/json"
+	"image"
+	"image/color"
 	"log"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
 )
 
-const doubleWOTHMarker = "*"
+// hintMarker is appended to a hint that was given twice.
+const hintMarker = "*"
 
-func (tracker *Tracker) AddWOTH(str string) bool {
-	// Handle Double-WOTH
-	// Each hint is doubled, two WoTH mean you have either seen all of them
-	// _or_ there might be two WoTH hints for the same area.
-	// A third WOTH will add a new line.
-	for k, v := range tracker.woths {
-		if v == str {
-			tracker.woths[k] += doubleWOTHMarker
-			return true
-		}
+// hintInputActionPrefix followed by a category name is the action starting the
+// text input of that category.
+const hintInputActionPrefix = "StartHintInput:"
+
+type hintMatch string
+
+const (
+	// A fuzzy-matched location, eg. WotH or barren, kept as text if unmatched.
+	hintMatchLocation hintMatch = "Location"
+	// A location and a goal target, eg. "dmc = light".
+	hintMatchGoal hintMatch = "Goal"
+	// A fixed slot, fuzzy-matched on the first word, then freeform text
+	// fuzzy-matched against items.
+	hintMatchSlot hintMatch = "Slot"
+	// A check and the item it holds, eg. "frogs 2 = bow".
+	hintMatchItem hintMatch = "Item"
+	// Freeform text.
+	hintMatchFreeform hintMatch = "Freeform"
+)
+
+type hintDuplicates string
+
+const (
+	// Entering a hint a second time marks it, a third time adds a new line.
+	hintDuplicatesMark hintDuplicates = "Mark"
+	// Duplicates are flagged, merging them marks the first one as confirmed.
+	hintDuplicatesConfirm hintDuplicates = "Confirm"
+	// Duplicates are flagged, merging them removes them.
+	hintDuplicatesFlag hintDuplicates = "Flag"
+	// Duplicates are neither flagged nor merged.
+	hintDuplicatesAllow hintDuplicates = "Allow"
+)
+
+// hintCategory is a kind of hint, eg. WotH or barren, as set in
+// hint_tracker.json. Hints are displayed in category order.
+type hintCategory struct {
+	Name       string
+	Bind       string      // key starting the text input
+	Color      color.RGBA  // background in the hint panel
+	MapColor   *color.RGBA // optional, color on the map if not Color
+	Match      hintMatch
+	Duplicates hintDuplicates
+	Slots      []hintSlot // for Slot categories, in display order
+
+	// Required locations contradict barren ones, both are shown on the map.
+	Required, Barren bool
+}
+
+type hintSlot struct {
+	Name string
+	Icon image.Point // sprite position on the items spritesheet
+}
+
+// hint is a single hint of a category, only the fields relevant to the
+// category are set.
+type hint struct {
+	Location string `json:",omitempty"`
+	Target   string `json:",omitempty"` // goal target
+	Slot     string `json:",omitempty"`
+	Check    string `json:",omitempty"`
+	Item     string `json:",omitempty"` // hinted item, for Item and Slot hints
+	Text     string `json:",omitempty"` // freeform text or unmatched input
+
+	// Given twice, or confirmed by merging duplicates.
+	Marked bool `json:",omitempty"`
+
+	// The hinted area was cleared.
+	Checked bool `json:",omitempty"`
+}
+
+// UnmarshalJSON also accepts freeform goal strings from older saves.
+func (h *hint) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*h = hint{Text: str}
+		return nil
 	}
 
-	tracker.woths = append(tracker.woths, str)
-	return true
+	type plain hint
+	return json.Unmarshal(b, (*plain)(h))
 }
 
-func (tracker *Tracker) AddBarren(str string) bool {
-	tracker.barrens = append(tracker.barrens, str)
-	return true
+// key identifies identical hints regardless of their marker and checked state.
+func (h hint) key() string {
+	return strings.ToLower(strings.Join([]string{h.Location, h.Target, h.Slot, h.Check, h.Item, h.Text}, goalSeparator))
 }
 
-func (tracker *Tracker) AddGoal(str string) bool {
-	tracker.goals = append(tracker.goals, str)
-	return true
+func (category hintCategory) color() color.RGBA {
+	return color.RGBA{category.Color.R, category.Color.G, category.Color.B, 0xFF}
 }
 
-func (tracker *Tracker) AddSometimes(str string) bool {
-	tracker.sometimes = append(tracker.sometimes, str)
-	return true
+func (category hintCategory) mapColor() color.RGBA {
+	if category.MapColor == nil {
+		return category.color()
+	}
+
+	return color.RGBA{category.MapColor.R, category.MapColor.G, category.MapColor.B, 0xFF}
 }
 
-func (tracker *Tracker) AddAlways(str string) bool {
-	index, item := tracker.parseAlways(str)
-	if index > -1 {
-		tracker.setAlways(index, item)
-		return true
+func (tracker *Tracker) getHintCategory(name string) (hintCategory, bool) {
+	for _, v := range tracker.cfg.HintTracker.Categories {
+		if v.Name == name {
+			return v, true
+		}
 	}
 
-	log.Printf("warning: could not parse %s", str)
-	return false
+	return hintCategory{}, false
 }
 
-func (tracker *Tracker) getAlwaysLocations() []string {
-	return []string{
-		"Skull Mask",
-		"Biggoron Sword",
-		"Ocarina of Time",
-		"Sheik at Kakariko",
-		"Frogs 2",
-		"30 Gold Skullutulas",
-		"40 Gold Skullutulas",
-		"50 Gold Skullutulas",
+// getHintInputCategory returns the index of the category whose text input is
+// started by the given action or -1.
+func (tracker *Tracker) getHintInputCategory(a action) int {
+	name, ok := strings.CutPrefix(string(a), hintInputActionPrefix)
+	if !ok {
+		return -1
 	}
+
+	return slices.IndexFunc(tracker.cfg.HintTracker.Categories, func(v hintCategory) bool {
+		return v.Name == name
+	})
 }
 
-func (tracker *Tracker) parseAlways(str string) (int, string) {
+// getLocationCategory returns the index of the first location category
+// holding barren or required locations, or -1.
+func (tracker *Tracker) getLocationCategory(barren bool) int {
+	return slices.IndexFunc(tracker.cfg.HintTracker.Categories, func(v hintCategory) bool {
+		return v.Match == hintMatchLocation && v.Barren == barren && (barren || v.Required)
+	})
+}
+
+func (tracker *Tracker) getSlotNames(category hintCategory) []string {
+	ret := make([]string, len(category.Slots))
+	for k, v := range category.Slots {
+		ret[k] = v.Name
+	}
+
+	return ret
+}
+
+// parseSlot reads the slot name until the first space, then the hint text,
+// an optional separator is skipped, eg. "oot = nocturne".
+func (tracker *Tracker) parseSlot(category hintCategory, str string) (hint, bool) {
 	parts := strings.SplitN(strings.Trim(str, " "), " ", 2)
 	if len(parts) < 2 {
 		parts = append(parts, "")
 	}
 	if parts[0] == "" {
-		return -1, ""
+		return hint{}, false
 	}
 
-	matches := fuzzy.RankFindFold(parts[0], tracker.getAlwaysLocations())
+	matches := fuzzy.RankFindFold(parts[0], tracker.getSlotNames(category))
 	if len(matches) == 0 {
-		return -1, ""
+		return hint{}, false
 	}
 
 	sort.Sort(matches)
-	switch matches[0].Target {
-	case "Skull Mask":
-		return 0, parts[1]
-	case "Biggoron Sword":
-		return 1, parts[1]
-	case "Ocarina of Time":
-		return 2, parts[1]
-	case "Sheik at Kakariko":
-		return 3, parts[1]
-	case "Frogs 2":
-		return 4, parts[1]
-	case "30 Gold Skullutulas":
-		return 5, parts[1]
-	case "40 Gold Skullutulas":
-		return 6, parts[1]
-	case "50 Gold Skullutulas":
-		return 7, parts[1]
-	}
-
-	return -1, ""
-}
-
-func (tracker *Tracker) setAlways(index int, str string) {
-	if index < 0 || index >= len(tracker.always) {
-		log.Printf(`bad index in setAlways(%d, "%s")`, index, str)
-		return
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(parts[1]), goalSeparator))
+	if item := tracker.matchSlotItem(text); item != "" {
+		return hint{Slot: matches[0].Target, Item: item}, true
+	}
+
+	return hint{Slot: matches[0].Target, Text: text}, true
+}
+
+// matchSlotItem returns the item named by the text of a slot hint. Only full
+// item names and single words are matched so longer text is not replaced by
+// an unrelated item, eg. "light arrows in spirit".
+func (tracker *Tracker) matchSlotItem(text string) string {
+	for _, v := range tracker.itemNames {
+		if strings.EqualFold(v, text) {
+			return v
+		}
+	}
+
+	if len(strings.Fields(text)) != 1 {
+		return ""
+	}
+
+	return tracker.matchItem(text)
+}
+
+// parseHint reads a hint of the given category from user input.
+func (tracker *Tracker) parseHint(category hintCategory, str string) (hint, bool) {
+	if strings.TrimSpace(str) == "" {
+		return hint{}, false
+	}
+
+	switch category.Match {
+	case hintMatchLocation:
+		if location := tracker.matchLocation(str); location != "" {
+			return hint{Location: location}, true
+		}
+	case hintMatchGoal:
+		return tracker.parseGoal(str), true
+	case hintMatchSlot:
+		return tracker.parseSlot(category, str)
+	case hintMatchItem:
+		return tracker.parseItemHint(str), true
+	case hintMatchFreeform:
+	}
+
+	return hint{Text: str}, true
+}
+
+// getHintPreview returns what the text being typed matches, if anything.
+func (tracker *Tracker) getHintPreview(category hintCategory, str string) string {
+	h, ok := tracker.parseHint(category, str)
+	if !ok {
+		return ""
+	}
+
+	switch category.Match {
+	case hintMatchLocation:
+		return h.Location
+	case hintMatchGoal:
+		if h.Text == "" && h.Target != "" {
+			return h.Location + ", " + h.Target
+		}
+		return h.Location
+	case hintMatchSlot:
+		if h.Item != "" {
+			return h.Slot + ", " + h.Item
+		}
+		return h.Slot
+	case hintMatchItem:
+		if h.Item != "" {
+			return h.Check + ", " + h.Item
+		}
+	case hintMatchFreeform:
 	}
 
-	tracker.always[index] = str
+	return ""
+}
+
+func (tracker *Tracker) getInputCategory() hintCategory {
+	return tracker.cfg.HintTracker.Categories[tracker.input.textInputFor]
 }
 
 func (tracker *Tracker) submitTextInput() {
@@ -119,28 +261,144 @@ func (tracker *Tracker) submitTextInput() {
 	}
 
 	str := string(tracker.input.buf)
-	if tracker.input.textInputFor == hintTypeWOTH ||
-		tracker.input.textInputFor == hintTypeBarren {
-		if match := tracker.matchLocation(str); match != "" {
-			str = match
+	h, ok := tracker.parseHint(tracker.getInputCategory(), str)
+	if !ok {
+		log.Printf("warning: could not parse %s", str)
+		return
+	}
+
+	tracker.addHint(tracker.input.textInputFor, h)
+	tracker.linkPendingStone(tracker.getInputCategory().Name, h)
+}
+
+// addHint adds an undoable hint to the category at the given index.
+func (tracker *Tracker) addHint(index int, h hint) {
+	category := tracker.cfg.HintTracker.Categories[index]
+	entry := undoStackEntry{
+		IsHint:       true,
+		HintCategory: category.Name,
+		Hint:         &h,
+	}
+
+	tracker.insertHint(category, h, &entry)
+	tracker.appendEntryToUndoStack(entry)
+}
+
+// insertHint adds a hint to its category and stores what is needed to undo it
+// in the entry.
+func (tracker *Tracker) insertHint(category hintCategory, h hint, entry *undoStackEntry) {
+	if tracker.hints == nil {
+		tracker.hints = make(map[string][]hint, len(tracker.cfg.HintTracker.Categories))
+	}
+	hints := tracker.hints[category.Name]
+
+	switch {
+	case category.Match == hintMatchSlot:
+		if i := slices.IndexFunc(hints, func(v hint) bool { return v.Slot == h.Slot }); i >= 0 {
+			previous := hints[i]
+			entry.HintIndex, entry.HintPrevious = i, &previous
+			hints[i] = h
+			return
+		}
+
+	// Handle Double-WOTH
+	// Each hint is doubled, two WoTH mean you have either seen all of them
+	// _or_ there might be two WoTH hints for the same area.
+	// A third WOTH will add a new line.
+	case category.Duplicates == hintDuplicatesMark:
+		if i := slices.IndexFunc(hints, func(v hint) bool { return !v.Marked && v.key() == h.key() }); i >= 0 {
+			entry.HintIndex, entry.HintMarked = i, true
+			hints[i].Marked = true
+			return
 		}
 	}
 
-	var ok bool
-	switch tracker.input.textInputFor {
-	case hintTypeWOTH:
-		ok = tracker.AddWOTH(str)
-	case hintTypeGoal:
-		ok = tracker.AddGoal(str)
-	case hintTypeBarren:
-		ok = tracker.AddBarren(str)
-	case hintTypeSometimes:
-		ok = tracker.AddSometimes(str)
-	case hintTypeAlways:
-		ok = tracker.AddAlways(str)
+	entry.HintIndex = len(hints)
+	tracker.hints[category.Name] = append(hints, h)
+}
+
+// removeHint reverts an insertHint.
+func (tracker *Tracker) removeHint(entry undoStackEntry) {
+	hints := tracker.hints[entry.HintCategory]
+	if entry.HintIndex >= len(hints) {
+		log.Printf("warning: no hint %d in '%s' to undo", entry.HintIndex, entry.HintCategory)
+		return
 	}
 
-	if ok {
-		tracker.appendHintToUndoStack(tracker.input.textInputFor, str)
+	switch {
+	case entry.HintMarked:
+		hints[entry.HintIndex].Marked = false
+	case entry.HintPrevious != nil:
+		hints[entry.HintIndex] = *entry.HintPrevious
+	default:
+		tracker.hints[entry.HintCategory] = slices.Delete(hints, entry.HintIndex, entry.HintIndex+1)
 	}
 }
+
+// getCategoryHintsOrder returns the indices of the hints of a category in
+// display order.
+func (tracker *Tracker) getCategoryHintsOrder(category hintCategory) []int {
+	hints := tracker.hints[category.Name]
+	ret := make([]int, 0, len(hints))
+
+	if category.Match != hintMatchSlot {
+		for k := range hints {
+			ret = append(ret, k)
+		}
+		return ret
+	}
+
+	for _, slot := range category.Slots {
+		if i := slices.IndexFunc(hints, func(v hint) bool { return v.Slot == slot.Name }); i >= 0 {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// hintText returns the text to display for a hint, its icon is drawn
+// separately.
+func (tracker *Tracker) hintText(h hint) string {
+	var str string
+	switch {
+	case h.Item != "" && h.Check == "":
+		str = h.Item
+	case h.Check != "":
+		str = h.Check
+	case h.Slot != "" || h.Location == "":
+		str = h.Text
+	default:
+		str = tracker.locationText(h.Location)
+		if h.Target != "" && tracker.goalIcon(h) == nil {
+			str += ", " + h.Target
+		}
+	}
+
+	if h.Marked {
+		str += hintMarker
+	}
+
+	return str
+}
+
+// hintIcons returns the sprites drawn next to a hint: its slot, goal target
+// and hinted item, if any.
+func (tracker *Tracker) hintIcons(category hintCategory, h hint) []image.Rectangle {
+	var ret []image.Rectangle
+	for _, v := range category.Slots {
+		if v.Name == h.Slot {
+			ret = append(ret, *spriteRect(v.Icon))
+		}
+	}
+
+	if icon := tracker.goalIcon(h); icon != nil {
+		ret = append(ret, *icon)
+	}
+
+	if icon := tracker.itemIcon(h); icon != nil {
+		ret = append(ret, *icon)
+	}
+
+	return ret
+}
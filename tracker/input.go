@@ -1,9 +1,9 @@
 package tracker
 
 import (
+	"fmt"
 	"log"
 	"sort"
-	"strings"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
 )
@@ -17,20 +17,19 @@ type kbInput struct {
 	// Temple of Time.
 	curMedallion int
 
+	// Vanilla/MQ input uses DungeonModeInputExtraKP instead of the dungeon
+	// input layout.
+	dungeonModeExtra bool
+
 	buf          []rune // text input buffer
-	textInputFor hintType
-}
+	textInputFor int    // index in HintTracker.Categories
 
-type hintType int
+	// Entrance matched by the first step of an entrance input.
+	entranceSource string
 
-const (
-	_ hintType = iota
-	hintTypeWOTH
-	hintTypeGoal
-	hintTypeBarren
-	hintTypeSometimes
-	hintTypeAlways
-)
+	// Index in the hint panel entries.
+	selectedHint int
+}
 
 type inputState int
 
@@ -48,6 +47,24 @@ const (
 
 	// Quick dungeons input for stones/medallions.
 	inputStateDungeonInput
+
+	// Writing the Temple of Time altar text.
+	inputStateAltarInput
+
+	// Toggling vanilla/MQ dungeons using the dungeon input layout.
+	inputStateDungeonModeInput
+
+	// Writing a song and its warp destination for a fuzzy search.
+	inputStateSongInput
+
+	// Writing an entrance then where it leads for a fuzzy search.
+	inputStateEntranceInput
+
+	// Moving in the hint panel to check hints.
+	inputStateHintSelection
+
+	// Writing a gossip stone name for a fuzzy search.
+	inputStateGossipInput
 )
 
 func (tracker *Tracker) kbInputStateIs(v inputState) bool {
@@ -73,7 +90,7 @@ func (tracker *Tracker) Input(input []rune) {
 		return
 	}
 
-	if tracker.input.state == inputStateTextInput {
+	if tracker.EatInput() {
 		tracker.input.buf = append(tracker.input.buf, input...)
 		return
 	}
@@ -84,6 +101,12 @@ func (tracker *Tracker) Input(input []rune) {
 }
 
 func (tracker *Tracker) idleHandleAction(a action) {
+	if index := tracker.getHintInputCategory(a); index >= 0 {
+		tracker.input.state = inputStateTextInput
+		tracker.input.textInputFor = index
+		return
+	}
+
 	switch a {
 	case actionIgnore:
 		return
@@ -96,6 +119,26 @@ func (tracker *Tracker) idleHandleAction(a action) {
 		tracker.input.curMedallion = 0
 		tracker.input.state = inputStateDungeonInput
 
+	case actionStartAltarInput:
+		tracker.input.state = inputStateAltarInput
+
+	case actionStartDungeonModeInput:
+		tracker.input.state = inputStateDungeonModeInput
+
+	case actionStartSongInput:
+		tracker.input.state = inputStateSongInput
+
+	case actionStartEntranceInput:
+		tracker.input.state = inputStateEntranceInput
+
+	case actionStartGossipInput:
+		tracker.input.state = inputStateGossipInput
+
+	case actionStartHintSelection:
+		layout := tracker.getHintPanelLayout(len(tracker.getDrawableHintList()))
+		tracker.input.selectedHint = min(tracker.hintPage, layout.pages-1) * layout.perPage()
+		tracker.input.state = inputStateHintSelection
+
 	case actionDowngradeNext:
 		tracker.input.state = inputStateItemKPZoneInput
 		tracker.input.downgradeNextItem = !tracker.input.downgradeNextItem
@@ -107,21 +150,10 @@ func (tracker *Tracker) idleHandleAction(a action) {
 		tracker.input.activeKPZone = actionToKPZone(a)
 		tracker.input.state = inputStateItemInput
 
-	case actionStartWOTHInput:
-		tracker.input.state = inputStateTextInput
-		tracker.input.textInputFor = hintTypeWOTH
-	case actionStartGoalInput:
-		tracker.input.state = inputStateTextInput
-		tracker.input.textInputFor = hintTypeGoal
-	case actionStartBarrenInput:
-		tracker.input.state = inputStateTextInput
-		tracker.input.textInputFor = hintTypeBarren
-	case actionStartAlwaysHintInput:
-		tracker.input.state = inputStateTextInput
-		tracker.input.textInputFor = hintTypeAlways
-	case actionStartSometimesHintInput:
-		tracker.input.state = inputStateTextInput
-		tracker.input.textInputFor = hintTypeSometimes
+	case actionNextHintPage:
+		tracker.ScrollHints(true)
+	case actionMergeDuplicateHints:
+		tracker.MergeDuplicateHints()
 
 	case actionRedo:
 		tracker.redo()
@@ -138,9 +170,12 @@ func (tracker *Tracker) IsIdle() bool {
 
 //nolint:funlen
 func (tracker *Tracker) inputAction(a action) {
+	tracker.setMessage("")
+
 	// Ensure we can _always_ leave using KP0 or Escape
 	if a == actionCancel || (a == actionStartItemInput && !tracker.kbInputStateIs(inputStateIdle)) {
 		tracker.input.reset()
+		tracker.pendingStone = ""
 		return
 	}
 
@@ -153,6 +188,42 @@ func (tracker *Tracker) inputAction(a action) {
 			tracker.submitTextInput()
 		}
 
+	case inputStateDungeonModeInput:
+		switch a { //nolint:exhaustive
+		case actionDowngradeNext:
+			tracker.input.downgradeNextItem = !tracker.input.downgradeNextItem
+			return
+		case actionStartDungeonModeInput:
+			tracker.input.dungeonModeExtra = !tracker.input.dungeonModeExtra
+			return
+		}
+
+		tracker.inputDungeonMode(a)
+
+	case inputStateSongInput:
+		if a == actionSubmit {
+			tracker.submitSongInput()
+		}
+
+	case inputStateEntranceInput:
+		if a == actionSubmit {
+			tracker.submitEntranceInput()
+		}
+
+	case inputStateHintSelection:
+		tracker.inputHintSelection(a)
+
+	case inputStateGossipInput:
+		if a == actionSubmit {
+			tracker.submitGossipInput()
+		}
+
+	case inputStateAltarInput:
+		if a == actionSubmit {
+			tracker.reportAltar(tracker.ParseAltar(string(tracker.input.buf)))
+			tracker.input.reset()
+		}
+
 	case inputStateItemKPZoneInput:
 		switch a { //nolint:exhaustive
 		case actionDowngradeNext:
@@ -181,7 +252,7 @@ func (tracker *Tracker) inputAction(a action) {
 
 	case inputStateDungeonInput:
 		defer func() {
-			// Reset / exit when all medallions are set, don't care about stones.
+			// Reset / exit when all medallions and stones are set.
 			if tracker.input.curMedallion >= len(tracker.cfg.ItemTracker.DungeonInputMedallionOrder) {
 				tracker.fillMissingMedallions()
 				tracker.input.reset()
@@ -221,10 +292,61 @@ func (tracker *Tracker) inputDungeon(a action) {
 		tracker.cfg.ItemTracker.DungeonInputMedallionOrder[tracker.input.curMedallion],
 	)
 
-	tracker.items[idx].SetDungeon(dungeon)
+	// A dungeon holds a single reward, refuse to assign it twice.
+	if other := tracker.getDungeonHolder(dungeon); other >= 0 && other != idx {
+		tracker.setMessage(fmt.Sprintf("%s already holds %s", dungeon, tracker.items[other].Name))
+		return
+	}
+
+	tracker.setDungeon(idx, dungeon)
 	tracker.input.curMedallion++
 }
 
+// getDungeonHolder returns the index of the stone or medallion assigned to
+// the given dungeon or -1.
+func (tracker *Tracker) getDungeonHolder(dungeon string) int {
+	dungeonIndex := tracker.getDungeonIndex(dungeon)
+	for k := range tracker.items {
+		if tracker.items[k].IsMedallion && tracker.items[k].DungeonIndex == dungeonIndex {
+			return k
+		}
+	}
+
+	return -1
+}
+
+// getUnassignedDungeons returns the short names of the dungeons that do not
+// hold a stone or medallion yet.
+func (tracker *Tracker) getUnassignedDungeons() []string {
+	var ret []string
+	for _, v := range tracker.cfg.ItemTracker.DungeonInputDungeonKP {
+		if tracker.getDungeonHolder(v) < 0 {
+			ret = append(ret, tracker.getDungeonText(Item{DungeonIndex: tracker.getDungeonIndex(v)}))
+		}
+	}
+
+	return ret
+}
+
+// getDuplicateDungeons returns the dungeon indexes assigned to more than one
+// stone or medallion.
+func (tracker *Tracker) getDuplicateDungeons() map[int]struct{} {
+	seen := make(map[int]struct{}, len(tracker.cfg.Dungeons))
+	ret := make(map[int]struct{})
+	for _, v := range tracker.items {
+		if !v.IsMedallion || v.DungeonIndex == 0 {
+			continue
+		}
+
+		if _, ok := seen[v.DungeonIndex]; ok {
+			ret[v.DungeonIndex] = struct{}{}
+		}
+		seen[v.DungeonIndex] = struct{}{}
+	}
+
+	return ret
+}
+
 func (tracker *Tracker) resetDungeons() {
 	for _, name := range tracker.getMedallionNames() {
 		idx := tracker.getItemIndexByName(name)
@@ -256,7 +378,7 @@ func (tracker *Tracker) fillMissingMedallions() {
 
 	missingDungeons := make([]int, 0, 3)
 	for _, v := range tracker.cfg.ItemTracker.DungeonInputDungeonKP {
-		idx := dungeonToDungeonIndex(v)
+		idx := tracker.getDungeonIndex(v)
 		if _, ok := dungeons[idx]; !ok {
 			missingDungeons = append(missingDungeons, idx)
 		}
@@ -330,22 +452,8 @@ func (tracker *Tracker) matchLocation(str string) string {
 		return ""
 	}
 
-	// HACK: Force some established conventions.
-	switch strings.ToLower(strings.Trim(str, " ")) {
-	case "dc":
-		str = "Dodongo's Cavern"
-	case "gv":
-		str = "Gerudo Valley"
-	case "gy":
-		str = "Graveyard"
-	case "gc":
-		str = "Goron City"
-	case "igc":
-		str = "Inside Ganon's Castle"
-	case "ogc":
-		str = "Outside Ganon's Castle"
-	case "sp":
-		str = "Spirit Temple"
+	if location := tracker.cfg.aliasToLocation(str); location != "" {
+		return location
 	}
 
 	matches := fuzzy.RankFindFold(str, tracker.cfg.Locations)
@@ -359,18 +467,21 @@ func (tracker *Tracker) matchLocation(str string) string {
 type action string
 
 const (
-	actionIgnore            action = "Ignore"
-	actionStartItemInput    action = "StartItemInput"
-	actionStartDungeonInput action = "StartDungeonInput"
-	actionDowngradeNext     action = "DowngradeNext"
-
-	actionStartWOTHInput          action = "StartWOTHInput"
-	actionStartGoalInput          action = "StartGoalInput"
-	actionStartBarrenInput        action = "StartBarrenInput"
-	actionStartAlwaysHintInput    action = "StartAlwaysHintInput"
-	actionStartSometimesHintInput action = "StartSometimesHintInput"
-	actionSubmit                  action = "Submit"
-	actionCancel                  action = "Cancel"
+	actionIgnore                action = "Ignore"
+	actionStartItemInput        action = "StartItemInput"
+	actionStartDungeonInput     action = "StartDungeonInput"
+	actionStartAltarInput       action = "StartAltarInput"
+	actionStartDungeonModeInput action = "StartDungeonModeInput"
+	actionStartSongInput        action = "StartSongInput"
+	actionStartEntranceInput    action = "StartEntranceInput"
+	actionStartHintSelection    action = "StartHintSelection"
+	actionStartGossipInput      action = "StartGossipInput"
+	actionDowngradeNext         action = "DowngradeNext"
+
+	actionNextHintPage        action = "NextHintPage"
+	actionMergeDuplicateHints action = "MergeDuplicateHints"
+	actionSubmit              action = "Submit"
+	actionCancel              action = "Cancel"
 
 	actionUndo action = "Undo"
 	actionRedo action = "Redo"
@@ -389,18 +500,25 @@ const (
 // runeToAction is the keyboard "binds" part, as we handle text input and not
 // keys we already are qwerty/azerty compatible but can't distinguish the main
 // keyboard from keypad.
+// Hint categories binds are used when a key is not in the binds config.
 func (tracker *Tracker) runeToAction(r rune) action {
 	a, ok := tracker.cfg.Binds[string([]rune{r})]
-	if !ok {
-		return actionIgnore
+	if ok {
+		return action(a)
 	}
 
-	return action(a)
+	for _, v := range tracker.cfg.HintTracker.Categories {
+		if v.Bind == string([]rune{r}) {
+			return action(hintInputActionPrefix + v.Name)
+		}
+	}
+
+	return actionIgnore
 }
 
 // Submit is called when the user presses Enter.
 func (tracker *Tracker) Submit() {
-	if !tracker.kbInputStateIs(inputStateTextInput) {
+	if !tracker.EatInput() {
 		return
 	}
 
@@ -422,5 +540,11 @@ func (tracker *Tracker) Backspace() {
 
 // EatInput returns true if the tracker should reserve all text inputs for itself.
 func (tracker *Tracker) EatInput() bool {
-	return tracker.kbInputStateIs(inputStateTextInput)
+	return tracker.kbInputStateIsAny(
+		inputStateTextInput,
+		inputStateAltarInput,
+		inputStateSongInput,
+		inputStateEntranceInput,
+		inputStateGossipInput,
+	)
 }
@@ -19,6 +19,9 @@ type Item struct {
 	// For countable items.
 	CountMax, CountStep, Count int
 
+	// Warp destination of a song.
+	Destination string `json:",omitempty"`
+
 	IsMedallion, IsSong, Enabled bool `json:",omitempty"`
 }
 
@@ -156,48 +159,3 @@ func (item *Item) HasCapacity() bool {
 func (item *Item) IsCountable() bool {
 	return item.CountMax != 0
 }
-
-var dungeons = []string{
-	"", "Free",
-	"Deku", "DC", "Jabu",
-	"Forest", "Fire", "Water",
-	"Spirit", "Shdw",
-}
-
-// map the dungeons global with actual dungeon names.
-func dungeonToDungeonIndex(str string) int {
-	return map[string]int{
-		"":                 0,
-		"Free":             1,
-		"Deku Tree":        2,
-		"Dodongo's Cavern": 3,
-		"Jabu Jabu":        4, //nolint:dupword
-		"Forest Temple":    5,
-		"Fire Temple":      6,
-		"Water Temple":     7,
-		"Spirit Temple":    8,
-		"Shadow Temple":    9,
-	}[str]
-}
-
-func (item *Item) SetDungeon(dungeon string) {
-	item.DungeonIndex = dungeonToDungeonIndex(dungeon)
-}
-
-func (item *Item) CycleDungeon(up bool) {
-	if up {
-		item.DungeonIndex = (item.DungeonIndex + 1) % len(dungeons)
-		return
-	}
-
-	if item.DungeonIndex-1 < 0 {
-		item.DungeonIndex = len(dungeons) - 1
-		return
-	}
-
-	item.DungeonIndex--
-}
-
-func (item *Item) DungeonText() string {
-	return dungeons[item.DungeonIndex]
-}
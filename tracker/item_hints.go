@@ -0,0 +1,99 @@
+package tracker
+
+import (
+	"image"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// loadItemSprites indexes the sprite position of every item and item upgrade
+// by name. Items with an ItemProgression are only matched by their upgrades,
+// they have no sprite of their own.
+func (tracker *Tracker) loadItemSprites() {
+	tracker.itemSprites = make(map[string]image.Point, len(tracker.cfg.Items))
+	for _, v := range tracker.cfg.Items {
+		if len(v.ItemProgression) == 0 {
+			tracker.itemSprites[v.Name] = image.Point{v.SheetX, v.SheetY}
+			continue
+		}
+
+		for _, upgrade := range v.ItemProgression {
+			if _, ok := tracker.itemSprites[upgrade.Name]; !ok && upgrade.Name != "" && upgrade.Name != "None" {
+				tracker.itemSprites[upgrade.Name] = image.Point{upgrade.SheetX, upgrade.SheetY}
+			}
+		}
+	}
+
+	tracker.itemNames = make([]string, 0, len(tracker.itemSprites))
+	for k := range tracker.itemSprites {
+		tracker.itemNames = append(tracker.itemNames, k)
+	}
+	sort.Strings(tracker.itemNames)
+}
+
+// matchItem returns the item or item upgrade best matching str or an empty
+// string.
+func (tracker *Tracker) matchItem(str string) string {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return ""
+	}
+
+	matches := fuzzy.RankFindFold(str, tracker.itemNames)
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Sort(matches)
+
+	return matches[0].Target
+}
+
+func (tracker *Tracker) matchCheck(str string) string {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return ""
+	}
+
+	matches := fuzzy.RankFindFold(str, tracker.cfg.Checks)
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Sort(matches)
+
+	return matches[0].Target
+}
+
+// parseItemHint reads a "check = item" hint, the check is matched against
+// the checks list and kept as typed if unmatched. Hints without a separator
+// or a known item are kept as freeform text.
+func (tracker *Tracker) parseItemHint(str string) hint {
+	str = strings.TrimSpace(str)
+
+	check, item, ok := strings.Cut(str, goalSeparator)
+	if !ok {
+		return hint{Text: str}
+	}
+
+	item = tracker.matchItem(item)
+	if item == "" {
+		return hint{Text: str}
+	}
+
+	if match := tracker.matchCheck(check); match != "" {
+		check = match
+	}
+
+	return hint{Check: strings.TrimSpace(check), Item: item}
+}
+
+// itemIcon returns the sprite of the hinted item on the items spritesheet.
+func (tracker *Tracker) itemIcon(h hint) *image.Rectangle {
+	pos, ok := tracker.itemSprites[h.Item]
+	if !ok {
+		return nil
+	}
+
+	return spriteRect(pos)
+}
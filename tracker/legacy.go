@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"log"
+	"slices"
+	"strings"
+)
+
+// legacyHints are the fixed hint lists of saves made before hint categories.
+type legacyHints struct {
+	WotHs, Barrens, Sometimes []string
+	Goals                     []hint
+	Always                    [8]string
+}
+
+// legacyAlwaysSlots is the order of the Always array of older saves.
+var legacyAlwaysSlots = [8]string{
+	"Skull Mask",
+	"Biggoron Sword",
+	"Ocarina of Time",
+	"Sheik at Kakariko",
+	"Frogs 2",
+	"30 Gold Skullutulas",
+	"40 Gold Skullutulas",
+	"50 Gold Skullutulas",
+}
+
+func (legacy legacyHints) isEmpty() bool {
+	return len(legacy.WotHs)+len(legacy.Barrens)+len(legacy.Sometimes)+len(legacy.Goals) == 0 &&
+		legacy.Always == [8]string{}
+}
+
+// loadLegacyHints converts the hints of an older save to the configured
+// categories, matched by name then by kind. The hints part of the undo history
+// can't be converted and is dropped.
+func (tracker *Tracker) loadLegacyHints(legacy legacyHints) {
+	tracker.hints = make(map[string][]hint)
+	add := func(name string, match func(hintCategory) bool, hints []hint) {
+		if len(hints) == 0 {
+			return
+		}
+
+		category := tracker.legacyCategory(name, match)
+		if category == "" {
+			log.Printf("warning: dropping %d %s hints of an older save, no matching category", len(hints), name)
+			return
+		}
+
+		tracker.hints[category] = append(tracker.hints[category], hints...)
+	}
+
+	add("WotH", func(c hintCategory) bool { return c.Match == hintMatchLocation && c.Required }, tracker.legacyLocations(legacy.WotHs))
+	add("Goal", func(c hintCategory) bool { return c.Match == hintMatchGoal }, legacy.Goals)
+	add("Barren", func(c hintCategory) bool { return c.Match == hintMatchLocation && c.Barren }, tracker.legacyLocations(legacy.Barrens))
+
+	sometimes := make([]hint, 0, len(legacy.Sometimes))
+	for _, v := range legacy.Sometimes {
+		sometimes = append(sometimes, hint{Text: v})
+	}
+	add("Sometimes", func(c hintCategory) bool { return c.Match == hintMatchItem || c.Match == hintMatchFreeform }, sometimes)
+
+	var always []hint
+	for k, v := range legacy.Always {
+		if v != "" {
+			always = append(always, hint{Slot: legacyAlwaysSlots[k], Text: v})
+		}
+	}
+	add("Always", func(c hintCategory) bool { return c.Match == hintMatchSlot }, always)
+
+	isHint := func(v undoStackEntry) bool { return v.IsHint }
+	if slices.ContainsFunc(tracker.undoStack, isHint) || slices.ContainsFunc(tracker.redoStack, isHint) {
+		log.Printf("warning: discarding the hints undo history of an older save")
+		tracker.undoStack = slices.DeleteFunc(tracker.undoStack, isHint)
+		tracker.redoStack = slices.DeleteFunc(tracker.redoStack, isHint)
+	}
+}
+
+// legacyCategory returns the category of the given name, or else the first
+// one matching, or an empty string.
+func (tracker *Tracker) legacyCategory(name string, match func(hintCategory) bool) string {
+	categories := tracker.cfg.HintTracker.Categories
+	if i := slices.IndexFunc(categories, func(c hintCategory) bool { return c.Name == name }); i >= 0 {
+		return name
+	}
+
+	if i := slices.IndexFunc(categories, match); i >= 0 {
+		return categories[i].Name
+	}
+
+	return ""
+}
+
+// legacyLocations reads location hints that had their markers appended.
+func (tracker *Tracker) legacyLocations(strs []string) []hint {
+	ret := make([]hint, 0, len(strs))
+	for _, v := range strs {
+		location := strings.TrimRight(v, hintMarker)
+		h := hint{Marked: location != v}
+		if slices.Contains(tracker.cfg.Locations, location) {
+			h.Location = location
+		} else {
+			h.Text = location
+		}
+
+		ret = append(ret, h)
+	}
+
+	return ret
+}
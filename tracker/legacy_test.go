@@ -0,0 +1,56 @@
+package tracker //nolint:testpackage // the save loading under test is unexported.
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const legacySave = `{
+	"WotHs": ["Lake Hylia*", "Not a location"],
+	"Barrens": ["Kokiri Forest"],
+	"Sometimes": ["Frogs 1 has Bow"],
+	"Goals": ["Forest Medallion: Lake Hylia"],
+	"Always": ["", "Hookshot", "", "", "", "", "", "Bottle"],
+	"UndoStack": [{"IsHint": true}, {"IsUpgrade": true}],
+	"RedoStack": [{"IsHint": true}]
+}`
+
+func TestLoadLegacyHints(t *testing.T) {
+	tracker := newTestTracker(t)
+	if err := tracker.loadJSON(strings.NewReader(legacySave)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]hint{
+		"WotH":      {{Location: "Lake Hylia", Marked: true}, {Text: "Not a location"}},
+		"Goal":      {{Text: "Forest Medallion: Lake Hylia"}},
+		"Barren":    {{Location: "Kokiri Forest"}},
+		"Sometimes": {{Text: "Frogs 1 has Bow"}},
+		"Always":    {{Slot: "Biggoron Sword", Text: "Hookshot"}, {Slot: "50 Gold Skullutulas", Text: "Bottle"}},
+	}
+	if !reflect.DeepEqual(tracker.hints, expected) {
+		t.Errorf("expected %v, got %v", expected, tracker.hints)
+	}
+
+	if len(tracker.undoStack) != 1 || !tracker.undoStack[0].IsUpgrade || len(tracker.redoStack) != 0 {
+		t.Errorf("expected only the item undo entry to be kept, got %v and %v", tracker.undoStack, tracker.redoStack)
+	}
+}
+
+func TestLoadLegacyHintsByKind(t *testing.T) {
+	tracker := newTestTracker(t)
+	for k := range tracker.cfg.HintTracker.Categories {
+		tracker.cfg.HintTracker.Categories[k].Name += " (renamed)"
+	}
+
+	if err := tracker.loadJSON(strings.NewReader(legacySave)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"WotH", "Goal", "Barren", "Sometimes", "Always"} {
+		if len(tracker.hints[name+" (renamed)"]) == 0 {
+			t.Errorf("expected the %s hints to be matched by kind, got %v", name, tracker.hints)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package tracker
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type mapConfig struct {
+	Image   string // optional background image, drawn at the panel origin
+	Regions map[string]mapRegion
+}
+
+// mapRegion is the position of a location on the map, all coordinates are
+// relative to the map panel origin.
+type mapRegion struct {
+	Pos     image.Point   // marker position
+	Polygon []image.Point `json:",omitempty"` // optional outline of the region
+}
+
+// Contains returns true if the given point relative to the map origin is
+// inside the region outline or on its marker.
+func (region mapRegion) Contains(p image.Point) bool {
+	d := p.Sub(region.Pos)
+	if d.X*d.X+d.Y*d.Y <= mapMarkerRadius*mapMarkerRadius {
+		return true
+	}
+
+	// Ray casting, count how many edges we cross going right.
+	var inside bool
+	for i, j := 0, len(region.Polygon)-1; i < len(region.Polygon); j, i = i, i+1 {
+		a, b := region.Polygon[i], region.Polygon[j]
+		if (a.Y > p.Y) == (b.Y > p.Y) {
+			continue
+		}
+
+		if p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+const mapMarkerRadius = 5
+
+func (tracker *Tracker) mapEnabled() bool {
+	return !tracker.cfg.Layout.Map.Empty()
+}
+
+// getRegionColor returns the map color of the first required or barren
+// category hinting the given location, categories being in precedence order.
+func (tracker *Tracker) getRegionColor(location string) color.RGBA {
+	for _, category := range tracker.cfg.HintTracker.Categories {
+		if !category.Required && !category.Barren {
+			continue
+		}
+
+		for _, v := range tracker.hints[category.Name] {
+			if v.Location == location {
+				return tracker.theme.HintMapColor(category.Name, category.mapColor())
+			}
+		}
+	}
+
+	return color.RGBA(tracker.theme.UnknownRegion)
+}
+
+// getRegionAt returns the location under the given screen point or an empty
+// string if there is none.
+func (tracker *Tracker) getRegionAt(x, y int) string {
+	p := image.Point{x, y}
+	if !tracker.mapEnabled() || !p.In(tracker.cfg.Layout.Map) {
+		return ""
+	}
+
+	p = p.Sub(tracker.cfg.Layout.Map.Min)
+	for _, location := range tracker.cfg.Locations {
+		region, ok := tracker.cfg.Map.Regions[location]
+		if ok && region.Contains(p) {
+			return location
+		}
+	}
+
+	return ""
+}
+
+// clickRegion adds a required or barren hint for the given location, if a
+// location hint input is in progress the location is used to complete it.
+func (tracker *Tracker) clickRegion(location string, barren bool) {
+	index := tracker.getLocationCategory(barren)
+	if tracker.kbInputStateIs(inputStateTextInput) {
+		if tracker.getInputCategory().Match != hintMatchLocation {
+			return
+		}
+		index = tracker.input.textInputFor
+	}
+
+	if index < 0 {
+		return
+	}
+
+	tracker.input.reset()
+	tracker.addHint(index, hint{Location: location})
+}
+
+// getHighlightedRegion returns the location currently being typed in a
+// location hint input.
+func (tracker *Tracker) getHighlightedRegion() string {
+	if !tracker.kbInputStateIs(inputStateTextInput) || tracker.getInputCategory().Match != hintMatchLocation {
+		return ""
+	}
+
+	return tracker.matchLocation(string(tracker.input.buf))
+}
+
+func (tracker *Tracker) drawMap(screen *ebiten.Image) {
+	if !tracker.mapEnabled() {
+		return
+	}
+
+	bounds := tracker.cfg.Layout.Map
+	if tracker.mapBackground != nil {
+		op := ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+		screen.DrawImage(tracker.mapBackground, &op)
+	} else {
+		vector.DrawFilledRect(
+			screen,
+			float32(bounds.Min.X), float32(bounds.Min.Y),
+			float32(bounds.Dx()), float32(bounds.Dy()),
+			tracker.theme.PanelBackground,
+			false,
+		)
+	}
+
+	var (
+		highlighted = tracker.getHighlightedRegion()
+		hovered     = tracker.getRegionAt(tracker.cursor.X, tracker.cursor.Y)
+	)
+
+	for _, location := range tracker.cfg.Locations {
+		region, ok := tracker.cfg.Map.Regions[location]
+		if !ok {
+			continue
+		}
+
+		clr := tracker.getRegionColor(location)
+		if len(region.Polygon) > 2 {
+			tracker.drawPolygon(screen, bounds.Min, region.Polygon, color.RGBA{clr.R / 2, clr.G / 2, clr.B / 2, 0x80})
+		}
+
+		pos := bounds.Min.Add(region.Pos)
+		vector.DrawFilledCircle(screen, float32(pos.X), float32(pos.Y), mapMarkerRadius, clr, true)
+		if location == highlighted || location == hovered {
+			vector.StrokeCircle(screen, float32(pos.X), float32(pos.Y), mapMarkerRadius+2, 2, color.White, true)
+		}
+	}
+
+	if hovered != "" {
+		tracker.drawTooltip(screen, hovered, bounds)
+	}
+}
+
+// drawPolygon fills a polygon whose points are relative to origin with the
+// given premultiplied color.
+func (tracker *Tracker) drawPolygon(screen *ebiten.Image, origin image.Point, polygon []image.Point, clr color.Color) {
+	var path vector.Path
+	for k, v := range polygon {
+		p := origin.Add(v)
+		if k == 0 {
+			path.MoveTo(float32(p.X), float32(p.Y))
+			continue
+		}
+		path.LineTo(float32(p.X), float32(p.Y))
+	}
+	path.Close()
+
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	r, g, b, a := clr.RGBA()
+	for i := range vs {
+		vs[i].SrcX, vs[i].SrcY = 1, 1
+		vs[i].ColorR = float32(r) / 0xFFFF
+		vs[i].ColorG = float32(g) / 0xFFFF
+		vs[i].ColorB = float32(b) / 0xFFFF
+		vs[i].ColorA = float32(a) / 0xFFFF
+	}
+
+	op := &ebiten.DrawTrianglesOptions{
+		ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha,
+		FillRule:       ebiten.FillRuleNonZero,
+		AntiAlias:      true,
+	}
+	screen.DrawTriangles(vs, is, tracker.whitePixel, op)
+}
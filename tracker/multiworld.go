@@ -0,0 +1,143 @@
+package tracker
+
+import (
+	"image"
+	"ivan/multiworld"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const multiworldLogLinePadding = 4
+
+// receivedItem is an entry of the multiworld log, Tracked is false for items
+// that are not on the tracker, eg. rupees or keys.
+type receivedItem struct {
+	From, Item string
+	Tracked    bool `json:",omitempty"`
+}
+
+func (tracker *Tracker) multiworldLogEnabled() bool {
+	return !tracker.cfg.Layout.MultiworldLog.Empty()
+}
+
+// ReceivedItemCount returns how many multiworld items were already received.
+func (tracker *Tracker) ReceivedItemCount() int {
+	return len(tracker.received)
+}
+
+// ReceiveItems adds the multiworld items sent by other players to the log and
+// upgrades the matching tracker items, each item being its own undo step. It
+// returns true if the log changed, and true for missing if items were skipped
+// by the server and must be asked for again.
+func (tracker *Tracker) ReceiveItems(items []multiworld.Item) (changed, missing bool) {
+	for _, v := range items {
+		if v.Index < len(tracker.received) {
+			continue // already logged
+		}
+
+		if v.Index > len(tracker.received) {
+			log.Printf(
+				"warning: multiworld: missing items %d to %d, asking for them again",
+				len(tracker.received), v.Index-1,
+			)
+			return changed, true
+		}
+
+		index := tracker.getReceivedItemIndex(v.Item)
+		tracker.received = append(tracker.received, receivedItem{
+			From:    v.From,
+			Item:    v.Item,
+			Tracked: index >= 0,
+		})
+		if index >= 0 {
+			tracker.applyReceivedItem(index, v)
+		}
+
+		log.Printf("info: multiworld: %s sent %s", v.From, v.Item)
+		changed = true
+	}
+
+	return changed, false
+}
+
+// getReceivedItemIndex returns the index of the tracker item matching a
+// multiworld item name or -1 if there is none.
+func (tracker *Tracker) getReceivedItemIndex(name string) int {
+	candidates, ok := tracker.cfg.Multiworld.Items[name]
+	if !ok {
+		candidates = []string{name}
+	}
+
+	if len(candidates) == 1 {
+		return tracker.getItemIndexByName(candidates[0])
+	}
+
+	for _, v := range candidates {
+		index := tracker.getItemIndexByName(v)
+		if index >= 0 && !tracker.items[index].Enabled {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// applyReceivedItem upgrades the item at the given index, countable items are
+// only upgraded every CountStep received items.
+func (tracker *Tracker) applyReceivedItem(index int, v multiworld.Item) {
+	if item := tracker.items[index]; item.Enabled && item.IsCountable() {
+		var count int
+		for _, entry := range tracker.received {
+			if entry.Item == v.Item {
+				count++
+			}
+		}
+
+		if count%max(1, item.CountStep) != 0 {
+			return
+		}
+	}
+
+	tracker.upgradeItemTagged(index, undoSourceMultiworld, v.From)
+}
+
+// drawMultiworldLog lists the received items, most recent first.
+func (tracker *Tracker) drawMultiworldLog(screen *ebiten.Image) {
+	if !tracker.multiworldLogEnabled() {
+		return
+	}
+
+	bounds := tracker.cfg.Layout.MultiworldLog
+	vector.DrawFilledRect(
+		screen,
+		float32(bounds.Min.X), float32(bounds.Min.Y),
+		float32(bounds.Dx()), float32(bounds.Dy()),
+		tracker.theme.PanelBackground,
+		false,
+	)
+
+	var (
+		lineHeight = tracker.smallFontSize() + multiworldLogLinePadding
+		pos        = bounds.Min.Add(image.Point{2, multiworldLogLinePadding / 2})
+		op         = &text.DrawOptions{}
+	)
+
+	for k := len(tracker.received) - 1; k >= 0 && pos.Y+lineHeight <= bounds.Max.Y; k-- {
+		entry := tracker.received[k]
+		clr := tracker.theme.Text
+		if !entry.Tracked {
+			clr = tracker.theme.Dimmed
+		}
+
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(pos.X), float64(pos.Y))
+		op.ColorScale.Reset()
+		op.ColorScale.ScaleWithColor(clr)
+		str := ellipsize(entry.From+": "+entry.Item, tracker.fontSmall, float64(bounds.Dx()-4))
+		text.Draw(screen, str, tracker.fontSmall, op)
+		pos.Y += lineHeight
+	}
+}
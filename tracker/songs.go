@@ -0,0 +1,124 @@
+package tracker
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// warpSongs are the songs that can have a warp destination.
+var warpSongs = []string{
+	"Minuet of Forest",
+	"Bolero of Fire",
+	"Serenade of Water",
+	"Requiem of Spirit",
+	"Nocturne of Shadow",
+	"Prelude of Light",
+}
+
+func (tracker *Tracker) getWarpSongNames() []string {
+	var ret []string
+	for _, v := range tracker.items {
+		if v.IsSong && slices.Contains(warpSongs, v.Name) {
+			ret = append(ret, v.Name)
+		}
+	}
+
+	return ret
+}
+
+// matchWarpSong returns the warp song starting with str, or the closest fuzzy
+// match, or an empty string.
+func (tracker *Tracker) matchWarpSong(str string) string {
+	names := tracker.getWarpSongNames()
+	for _, v := range names {
+		if len(str) <= len(v) && strings.EqualFold(v[:len(str)], str) {
+			return v
+		}
+	}
+
+	matches := fuzzy.RankFindFold(str, names)
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Sort(matches)
+
+	return matches[0].Target
+}
+
+// parseSongDestination reads a warp song and its destination from user input,
+// the song is the first word and the destination the rest, both fuzzy-matched.
+// It returns the index of the song item (or -1), the matched location, and
+// false if a destination was typed but not matched.
+func (tracker *Tracker) parseSongDestination(str string) (int, string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(str), " ", 2)
+	if parts[0] == "" {
+		return -1, "", false
+	}
+
+	song := tracker.matchWarpSong(parts[0])
+	if song == "" {
+		return -1, "", false
+	}
+
+	var location string
+	if len(parts) > 1 {
+		location = tracker.matchLocation(strings.TrimSpace(parts[1]))
+		if location == "" && strings.TrimSpace(parts[1]) != "" {
+			return tracker.getItemIndexByName(song), "", false
+		}
+	}
+
+	return tracker.getItemIndexByName(song), location, true
+}
+
+func (tracker *Tracker) submitSongInput() {
+	defer tracker.input.reset()
+
+	str := string(tracker.input.buf)
+	index, location, ok := tracker.parseSongDestination(str)
+	if !ok {
+		log.Printf("warning: could not parse %s", str)
+		return
+	}
+
+	tracker.setSongDestination(index, location)
+}
+
+// setSongDestination sets the warp destination of a song in an undoable way.
+func (tracker *Tracker) setSongDestination(itemIndex int, location string) {
+	previous := tracker.items[itemIndex].Destination
+	if previous == location {
+		return
+	}
+
+	tracker.items[itemIndex].Destination = location
+	tracker.appendEntryToUndoStack(undoStackEntry{
+		ItemIndex:           itemIndex,
+		IsDestination:       true,
+		Destination:         location,
+		PreviousDestination: previous,
+	})
+}
+
+func (tracker *Tracker) drawSongDestinations(screen *ebiten.Image) {
+	var op = &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(tracker.theme.Text)
+
+	for k := range tracker.items {
+		if !tracker.items[k].IsSong || tracker.items[k].Destination == "" {
+			continue
+		}
+
+		rect := tracker.items[k].Rect()
+
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(rect.Min.X), float64(rect.Max.Y-tracker.smallFontSize()))
+		text.Draw(screen, tracker.cfg.shortLocation(tracker.items[k].Destination), tracker.fontSmall, op)
+	}
+}
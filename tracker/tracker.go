@@ -1,10 +1,11 @@
 package tracker
 
 import (
-	"bytes"
 	"encoding/json"
 	"image"
+	"image/color"
 	"io"
+	"ivan/theme"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,7 +13,6 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"golang.org/x/image/font/gofont/goregular"
 )
 
 type Tracker struct {
@@ -21,17 +21,35 @@ type Tracker struct {
 
 	background, backgroundHelp  *ebiten.Image
 	sheetDisabled, sheetEnabled *ebiten.Image
+	mapBackground               *ebiten.Image // optional
+	whitePixel                  *ebiten.Image // source for filled polygons
+	theme                       *theme.Theme
 	font, fontSmall             text.Face
 
-	items                            []Item
-	woths, goals, barrens, sometimes []string
-	always                           [8]string // in order: skull, bigg, OOT, sheik at kak, frogs 2, 30, 40, 50
+	cursor         image.Point // last known cursor position
+	hintPage       int
+	gossipPage     int
+	entranceScroll int    // first line displayed in the entrance list
+	message        string // feedback shown in place of the input state until the next input
+	pendingStone   string // gossip stone to link to the hint typed right after
+	pendingUndoLen int    // length of the undo stack when pendingStone was read
+
+	items        []Item
+	hints        map[string][]hint // category name to its hints
+	dungeonModes map[string]dungeonMode
+	entrances    map[string]string // source to destination entrance
+	stones       map[string]gossipStone
+	received     []receivedItem // multiworld items sent by other players
+
+	itemSprites map[string]image.Point // item and upgrade names to their sprite
+	itemNames   []string               // sorted keys of itemSprites
 
 	undoStack, redoStack []undoStackEntry
 }
 
-func New(cfg Config) (*Tracker, error) {
+func New(cfg Config, th *theme.Theme) (*Tracker, error) {
 	tracker := &Tracker{cfg: cfg}
+	tracker.SetTheme(th)
 
 	if err := tracker.loadResources(); err != nil {
 		return nil, err
@@ -39,19 +57,31 @@ func New(cfg Config) (*Tracker, error) {
 
 	tracker.resetItems()
 	tracker.setInitialItems()
+	tracker.loadItemSprites()
 
 	return tracker, nil
 }
 
+func (tracker *Tracker) setMessage(str string) {
+	tracker.message = str
+}
+
 func (tracker *Tracker) resetItems() {
 	tracker.items = make([]Item, len(tracker.cfg.Items))
 	copy(tracker.items, tracker.cfg.Items)
 }
 
-const (
-	trackerFontSize      = 20
-	trackerSmallFontSize = 13
-)
+// SetTheme changes the fonts and colors used to draw the tracker.
+func (tracker *Tracker) SetTheme(th *theme.Theme) {
+	tracker.theme = th
+	tracker.font = th.Face()
+	tracker.fontSmall = th.SmallFace()
+}
+
+// smallFontSize returns the size of the font used for most text, in pixels.
+func (tracker *Tracker) smallFontSize() int {
+	return int(tracker.theme.SmallFont.Size)
+}
 
 func (tracker *Tracker) loadResources() (err error) {
 	images := []struct {
@@ -64,6 +94,13 @@ func (tracker *Tracker) loadResources() (err error) {
 		{&tracker.sheetEnabled, "assets/items.png"},
 	}
 
+	if tracker.cfg.Map.Image != "" {
+		images = append(images, struct {
+			img  **ebiten.Image
+			path string
+		}{&tracker.mapBackground, tracker.cfg.Map.Image})
+	}
+
 	for _, v := range images {
 		*v.img, _, err = ebitenutil.NewImageFromFile(v.path)
 		if err != nil {
@@ -71,19 +108,9 @@ func (tracker *Tracker) loadResources() (err error) {
 		}
 	}
 
-	ttf, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
-	if err != nil {
-		return err
-	}
-
-	tracker.font = &text.GoTextFace{
-		Source: ttf,
-		Size:   trackerFontSize,
-	}
-	tracker.fontSmall = &text.GoTextFace{
-		Source: ttf,
-		Size:   trackerSmallFontSize,
-	}
+	white := ebiten.NewImage(3, 3)
+	white.Fill(color.White)
+	tracker.whitePixel = white.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
 
 	return nil
 }
@@ -153,8 +180,30 @@ func (tracker *Tracker) getItemIndexByName(name string) int {
 	return -1
 }
 
-// ClickLeft upgrades the item under the given point.
+// ClickLeft upgrades the item under the given point, adds a WotH hint for
+// the map region under it, cycles the mode of the dungeon label under it, or
+// checks the hint under it.
 func (tracker *Tracker) ClickLeft(x, y int) {
+	if location := tracker.getRegionAt(x, y); location != "" {
+		tracker.clickRegion(location, false)
+		return
+	}
+
+	if dungeon := tracker.getDungeonLabelAt(x, y); dungeon > 0 {
+		tracker.cycleDungeonMode(dungeon, true)
+		return
+	}
+
+	if dungeon := tracker.getDungeonPanelAt(x, y); dungeon > 0 {
+		tracker.cycleDungeonMode(dungeon, true)
+		return
+	}
+
+	if entry, ok := tracker.getHintAt(x, y); ok {
+		tracker.toggleHintChecked(entry.category, entry.index)
+		return
+	}
+
 	i := tracker.getItemIndexByPos(x, y)
 	if i < 0 {
 		return
@@ -163,8 +212,25 @@ func (tracker *Tracker) ClickLeft(x, y int) {
 	tracker.changeItem(i, true)
 }
 
-// ClickRight downgrades the item under the given point.
+// ClickRight downgrades the item under the given point, adds a barren hint
+// for the map region under it, or cycles back the mode of the dungeon label
+// under it.
 func (tracker *Tracker) ClickRight(x, y int) {
+	if location := tracker.getRegionAt(x, y); location != "" {
+		tracker.clickRegion(location, true)
+		return
+	}
+
+	if dungeon := tracker.getDungeonLabelAt(x, y); dungeon > 0 {
+		tracker.cycleDungeonMode(dungeon, false)
+		return
+	}
+
+	if dungeon := tracker.getDungeonPanelAt(x, y); dungeon > 0 {
+		tracker.cycleDungeonMode(dungeon, false)
+		return
+	}
+
 	i := tracker.getItemIndexByPos(x, y)
 	if i < 0 {
 		return
@@ -173,6 +239,30 @@ func (tracker *Tracker) ClickRight(x, y int) {
 	tracker.changeItem(i, false)
 }
 
+// upgradeItemTagged upgrades an item in an undo entry tagged with the source of
+// the upgrade. It returns false, adding no entry, if the item did not change,
+// eg. a countable item already at CountMax.
+func (tracker *Tracker) upgradeItemTagged(itemIndex int, source, from string) bool {
+	item := &tracker.items[itemIndex]
+	undoLen := len(tracker.undoStack)
+	enabled, upgradeIndex, count := item.Enabled, item.UpgradeIndex, item.Count
+	tracker.changeItem(itemIndex, true)
+	if len(tracker.undoStack) == undoLen {
+		return false // not upgradable
+	}
+
+	// Countable items "upgrade" without changing past CountMax.
+	if item.Enabled == enabled && item.UpgradeIndex == upgradeIndex && item.Count == count {
+		tracker.undoStack = tracker.undoStack[:undoLen]
+		return false
+	}
+
+	tracker.undoStack[undoLen].Source = source
+	tracker.undoStack[undoLen].From = from
+
+	return true
+}
+
 func (tracker *Tracker) changeItem(itemIndex int, isUpgrade bool) {
 	var fn func() bool
 	if isUpgrade {
@@ -186,15 +276,22 @@ func (tracker *Tracker) changeItem(itemIndex int, isUpgrade bool) {
 	}
 }
 
+// Wheel cycles the item under the given point or scrolls the hint panel.
 func (tracker *Tracker) Wheel(x, y int, up bool) {
 	i := tracker.getItemIndexByPos(x, y)
-	if i < 0 {
-		return
-	}
 
 	switch {
+	case i < 0:
+		switch p := (image.Point{x, y}); {
+		case p.In(tracker.cfg.Layout.HintTracker):
+			tracker.ScrollHints(!up)
+		case p.In(tracker.cfg.Layout.Entrances):
+			tracker.ScrollEntrances(!up)
+		case p.In(tracker.cfg.Layout.GossipStones):
+			tracker.ScrollGossipStones(!up)
+		}
 	case tracker.items[i].IsMedallion:
-		tracker.items[i].CycleDungeon(up)
+		tracker.cycleDungeon(i, up)
 	default:
 		if up {
 			tracker.ClickLeft(x, y)
@@ -210,11 +307,15 @@ func (tracker *Tracker) Reset() {
 
 	tracker.undoStack = tracker.undoStack[:0]
 	tracker.redoStack = tracker.redoStack[:0]
-	tracker.woths = tracker.woths[:0]
-	tracker.goals = tracker.goals[:0]
-	tracker.barrens = tracker.barrens[:0]
-	tracker.sometimes = tracker.sometimes[:0]
-	tracker.always = [8]string{}
+	tracker.hints = nil
+	tracker.dungeonModes = nil
+	tracker.entrances = nil
+	tracker.stones = nil
+	tracker.received = nil
+	tracker.pendingStone = ""
+	tracker.hintPage = 0
+	tracker.gossipPage = 0
+	tracker.entranceScroll = 0
 
 	if err := tracker.Save(); err != nil {
 		log.Printf("error: %s", err)
@@ -253,17 +354,20 @@ func getSavePath() string {
 
 func (tracker Tracker) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Items                            []Item
-		WotHs, Goals, Barrens, Sometimes []string
-		Always                           [8]string
-		UndoStack, RedoStack             []undoStackEntry
+		Items                []Item
+		Hints                map[string][]hint
+		DungeonModes         map[string]dungeonMode
+		Entrances            map[string]string
+		GossipStones         map[string]gossipStone
+		MultiworldLog        []receivedItem
+		UndoStack, RedoStack []undoStackEntry
 	}{
 		tracker.items,
-		tracker.woths,
-		tracker.goals,
-		tracker.barrens,
-		tracker.sometimes,
-		tracker.always,
+		tracker.hints,
+		tracker.dungeonModes,
+		tracker.entrances,
+		tracker.stones,
+		tracker.received,
 		tracker.undoStack,
 		tracker.redoStack,
 	})
@@ -271,10 +375,15 @@ func (tracker Tracker) MarshalJSON() ([]byte, error) {
 
 func (tracker *Tracker) loadJSON(r io.Reader) error {
 	var tmp struct {
-		Items                            []Item
-		WotHs, Goals, Barrens, Sometimes []string
-		Always                           [8]string
-		UndoStack, RedoStack             []undoStackEntry
+		Items                []Item
+		Hints                map[string][]hint
+		DungeonModes         map[string]dungeonMode
+		Entrances            map[string]string
+		GossipStones         map[string]gossipStone
+		MultiworldLog        []receivedItem
+		UndoStack, RedoStack []undoStackEntry
+
+		legacyHints
 	}
 
 	dec := json.NewDecoder(r)
@@ -283,14 +392,18 @@ func (tracker *Tracker) loadJSON(r io.Reader) error {
 	}
 
 	tracker.items = tmp.Items
-	tracker.woths = tmp.WotHs
-	tracker.goals = tmp.Goals
-	tracker.barrens = tmp.Barrens
-	tracker.sometimes = tmp.Sometimes
-	tracker.always = tmp.Always
+	tracker.hints = tmp.Hints
+	tracker.dungeonModes = tmp.DungeonModes
+	tracker.entrances = tmp.Entrances
+	tracker.stones = tmp.GossipStones
+	tracker.received = tmp.MultiworldLog
 	tracker.undoStack = tmp.UndoStack
 	tracker.redoStack = tmp.RedoStack
 
+	if tmp.Hints == nil && !tmp.legacyHints.isEmpty() {
+		tracker.loadLegacyHints(tmp.legacyHints)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,19 @@
+package tracker //nolint:testpackage // the parsers under test are unexported.
+
+import "testing"
+
+// newTestTracker returns a tracker using the default config, without the
+// theme and sprites that need a running game.
+func newTestTracker(t *testing.T) *Tracker {
+	t.Helper()
+
+	cfg, err := NewConfigFromDir("../config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := &Tracker{cfg: cfg}
+	tracker.resetItems()
+
+	return tracker
+}
@@ -2,66 +2,120 @@ package tracker
 
 import (
 	"log"
-	"strings"
 )
 
-// undoStackEntry represents an action (upgrade/downgrade) that happened on an item.
+// undoStackEntry represents an action (upgrade/downgrade) that happened on an
+// item, a hint that was added or merged, or a song destination change.
 type undoStackEntry struct {
-	HintText          string
-	HintType          hintType
 	ItemIndex         int
 	IsHint, IsUpgrade bool
+
+	// For hints, HintIndex is the position of the hint in its category.
+	// HintMarked is true if a marker was added instead of a new hint, and
+	// HintPrevious holds the replaced hint of a slot.
+	// For merged duplicate hints, HintIndex is the position of the removed
+	// duplicate Hint and HintMarked is true if the merge added a marker.
+	HintCategory        string `json:",omitempty"`
+	Hint, HintPrevious  *hint  `json:",omitempty"`
+	HintIndex           int    `json:",omitempty"`
+	IsMerge, HintMarked bool   `json:",omitempty"`
+
+	// For checked hints, toggling the hint at HintIndex both ways.
+	IsCheck bool `json:",omitempty"`
+
+	// For gossip stones, nil states being unread.
+	StoneName            string       `json:",omitempty"`
+	Stone, PreviousStone *gossipStone `json:",omitempty"`
+	IsStone              bool         `json:",omitempty"`
+
+	// For song warp destinations, on the item at ItemIndex, and entrances
+	// leading from EntranceSource.
+	Destination, PreviousDestination string `json:",omitempty"`
+	EntranceSource                   string `json:",omitempty"`
+	IsDestination, IsEntrance        bool   `json:",omitempty"`
+
+	// Chained entries are undone and redone along with the entry before them.
+	IsChained bool `json:",omitempty"`
+
+	// Source tags the actions that were not made by hand, From being the
+	// player who sent a multiworld item.
+	Source string `json:",omitempty"`
+	From   string `json:",omitempty"`
 }
 
-func (tracker *Tracker) appendHintToUndoStack(t hintType, str string) {
-	// If we were back in time, discard and replace history.
-	if len(tracker.redoStack) > 0 {
-		tracker.redoStack = nil
-	}
+// Sources of the automatic undo entries.
+const (
+	undoSourceAutoTracker = "auto-tracker"
+	undoSourceMultiworld  = "multiworld"
+)
 
-	tracker.undoStack = append(tracker.undoStack, undoStackEntry{
-		IsHint:   true,
-		HintType: t,
-		HintText: str,
+func (tracker *Tracker) appendToUndoStack(itemIndex int, isUpgrade bool) {
+	tracker.appendEntryToUndoStack(undoStackEntry{
+		ItemIndex: itemIndex,
+		IsUpgrade: isUpgrade,
 	})
 }
 
-func (tracker *Tracker) appendToUndoStack(itemIndex int, isUpgrade bool) {
+func (tracker *Tracker) appendEntryToUndoStack(entry undoStackEntry) {
 	// If we were back in time, discard and replace history.
 	if len(tracker.redoStack) > 0 {
 		tracker.redoStack = nil
 	}
 
-	tracker.undoStack = append(tracker.undoStack, undoStackEntry{
-		ItemIndex: itemIndex,
-		IsUpgrade: isUpgrade,
-	})
+	tracker.undoStack = append(tracker.undoStack, entry)
 }
 
 func (tracker *Tracker) undo() {
+	tracker.pendingStone = ""
 	if len(tracker.undoStack) == 0 {
 		log.Printf("no action to undo")
 		return
 	}
 
-	entry := tracker.undoStack[len(tracker.undoStack)-1]
-	tracker.undoStack = tracker.undoStack[:len(tracker.undoStack)-1]
-	tracker.redoStack = append(tracker.redoStack, entry)
+	for len(tracker.undoStack) > 0 {
+		entry := tracker.undoStack[len(tracker.undoStack)-1]
+		tracker.undoStack = tracker.undoStack[:len(tracker.undoStack)-1]
+		tracker.redoStack = append(tracker.redoStack, entry)
+		tracker.undoEntry(entry)
 
-	if entry.IsHint {
-		switch entry.HintType {
-		case hintTypeWOTH:
-			tracker.undoWOTH(entry)
-		case hintTypeGoal:
-			tracker.goals = tracker.goals[:len(tracker.goals)-1]
-		case hintTypeBarren:
-			tracker.barrens = tracker.barrens[:len(tracker.barrens)-1]
-		case hintTypeSometimes:
-			tracker.sometimes = tracker.sometimes[:len(tracker.sometimes)-1]
-		case hintTypeAlways:
-			index, _ := tracker.parseAlways(entry.HintText)
-			tracker.setAlways(index, "")
+		if entry.Source == undoSourceMultiworld {
+			log.Printf("info: undid %s sent by %s", tracker.items[entry.ItemIndex].Name, entry.From)
 		}
+
+		if !entry.IsChained {
+			return
+		}
+	}
+}
+
+func (tracker *Tracker) undoEntry(entry undoStackEntry) {
+	if entry.IsCheck {
+		tracker.setHintChecked(entry.HintCategory, entry.HintIndex)
+		return
+	}
+
+	if entry.IsMerge {
+		tracker.unmergeHint(entry)
+		return
+	}
+
+	if entry.IsDestination {
+		tracker.items[entry.ItemIndex].Destination = entry.PreviousDestination
+		return
+	}
+
+	if entry.IsEntrance {
+		tracker.setEntranceDestination(entry.EntranceSource, entry.PreviousDestination)
+		return
+	}
+
+	if entry.IsStone {
+		tracker.setGossipStone(entry.StoneName, entry.PreviousStone)
+		return
+	}
+
+	if entry.IsHint {
+		tracker.removeHint(entry)
 		return
 	}
 
@@ -72,46 +126,58 @@ func (tracker *Tracker) undo() {
 	}
 }
 
-func (tracker *Tracker) undoWOTH(entry undoStackEntry) {
-	// Clear last added double woth or last woth
-	double := entry.HintText + doubleWOTHMarker
-	for i := len(tracker.woths) - 1; i >= 0; i-- {
-		if tracker.woths[i] == double {
-			tracker.woths[i] = strings.TrimSuffix(tracker.woths[i], doubleWOTHMarker)
+func (tracker *Tracker) redo() {
+	tracker.pendingStone = ""
+	if len(tracker.redoStack) == 0 {
+		log.Printf("no action to redo")
+		return
+	}
+
+	for first := true; len(tracker.redoStack) > 0; first = false {
+		entry := tracker.redoStack[len(tracker.redoStack)-1]
+		if !first && !entry.IsChained {
 			return
 		}
+
+		tracker.redoStack = tracker.redoStack[:len(tracker.redoStack)-1]
+		tracker.undoStack = append(tracker.undoStack, entry)
+		tracker.redoEntry(entry)
 	}
+}
 
-	if len(tracker.woths) == 0 {
+func (tracker *Tracker) redoEntry(entry undoStackEntry) {
+	if entry.IsCheck {
+		tracker.setHintChecked(entry.HintCategory, entry.HintIndex)
 		return
 	}
 
-	tracker.woths = tracker.woths[:len(tracker.woths)-1]
-}
+	if entry.IsHint {
+		category, ok := tracker.getHintCategory(entry.HintCategory)
+		if !ok {
+			log.Printf("warning: unknown hint category '%s'", entry.HintCategory)
+			return
+		}
 
-func (tracker *Tracker) redo() {
-	if len(tracker.redoStack) == 0 {
-		log.Printf("no action to redo")
+		if entry.IsMerge {
+			tracker.mergeHint(category, &entry)
+		} else {
+			tracker.insertHint(category, *entry.Hint, &entry)
+		}
 		return
 	}
 
-	entry := tracker.redoStack[len(tracker.redoStack)-1]
-	tracker.redoStack = tracker.redoStack[:len(tracker.redoStack)-1]
-	tracker.undoStack = append(tracker.undoStack, entry)
+	if entry.IsDestination {
+		tracker.items[entry.ItemIndex].Destination = entry.Destination
+		return
+	}
 
-	if entry.IsHint {
-		switch entry.HintType {
-		case hintTypeWOTH:
-			tracker.AddWOTH(entry.HintText)
-		case hintTypeGoal:
-			tracker.AddGoal(entry.HintText)
-		case hintTypeBarren:
-			tracker.AddBarren(entry.HintText)
-		case hintTypeSometimes:
-			tracker.AddSometimes(entry.HintText)
-		case hintTypeAlways:
-			tracker.AddAlways(entry.HintText)
-		}
+	if entry.IsEntrance {
+		tracker.setEntranceDestination(entry.EntranceSource, entry.Destination)
+		return
+	}
+
+	if entry.IsStone {
+		tracker.setGossipStone(entry.StoneName, entry.Stone)
 		return
 	}
 
@@ -0,0 +1,108 @@
+package tracker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	unknownRegionsColumns     = 2
+	unknownRegionsLinePadding = 4
+)
+
+func (tracker *Tracker) unknownRegionsEnabled() bool {
+	return !tracker.cfg.Layout.UnknownRegions.Empty()
+}
+
+// getUnknownRegions returns the locations that were hinted neither as
+// required nor as barren, split between overworld and dungeons when dungeon
+// locations are configured.
+func (tracker *Tracker) getUnknownRegions() (overworld, dungeons []string) {
+	known := make(map[string]struct{})
+	for _, category := range tracker.cfg.HintTracker.Categories {
+		if !category.Required && !category.Barren {
+			continue
+		}
+
+		for _, v := range tracker.hints[category.Name] {
+			if v.Location != "" {
+				known[v.Location] = struct{}{}
+			}
+		}
+	}
+
+	for _, location := range tracker.cfg.Locations {
+		if _, ok := known[location]; ok {
+			continue
+		}
+
+		if slices.Contains(tracker.cfg.HintTracker.DungeonLocations, location) {
+			dungeons = append(dungeons, location)
+		} else {
+			overworld = append(overworld, location)
+		}
+	}
+
+	return overworld, dungeons
+}
+
+func (tracker *Tracker) drawUnknownRegions(screen *ebiten.Image) {
+	if !tracker.unknownRegionsEnabled() {
+		return
+	}
+
+	bounds := tracker.cfg.Layout.UnknownRegions
+	vector.DrawFilledRect(
+		screen,
+		float32(bounds.Min.X), float32(bounds.Min.Y),
+		float32(bounds.Dx()), float32(bounds.Dy()),
+		tracker.theme.PanelBackground,
+		false,
+	)
+
+	var (
+		overworld, dungeons = tracker.getUnknownRegions()
+		lineHeight          = tracker.smallFontSize() + unknownRegionsLinePadding
+		columnWidth         = bounds.Dx() / unknownRegionsColumns
+		pos                 = bounds.Min.Add(image.Point{2, unknownRegionsLinePadding / 2})
+		op                  = &text.DrawOptions{}
+	)
+
+	drawLine := func(str string, x int, clr color.Color) {
+		if pos.Y+lineHeight > bounds.Max.Y {
+			return
+		}
+
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(pos.X+x), float64(pos.Y))
+		op.ColorScale.Reset()
+		op.ColorScale.ScaleWithColor(clr)
+		text.Draw(screen, ellipsize(str, tracker.fontSmall, float64(columnWidth-4)), tracker.fontSmall, op)
+	}
+
+	drawSection := func(title string, locations []string) {
+		drawLine(fmt.Sprintf("%s (%d)", title, len(locations)), 0, tracker.theme.PanelHeader)
+		pos.Y += lineHeight
+
+		for k, v := range locations {
+			drawLine(tracker.cfg.shortLocation(v), (k%unknownRegionsColumns)*columnWidth, tracker.theme.Text)
+			if k%unknownRegionsColumns == unknownRegionsColumns-1 || k == len(locations)-1 {
+				pos.Y += lineHeight
+			}
+		}
+	}
+
+	if len(tracker.cfg.HintTracker.DungeonLocations) == 0 {
+		drawSection("Unknown", overworld)
+		return
+	}
+
+	drawSection("Unknown overworld", overworld)
+	drawSection("Unknown dungeons", dungeons)
+}
@@ -0,0 +1,171 @@
+package tracker
+
+import (
+	"slices"
+)
+
+type hintIssue int
+
+const (
+	hintIssueNone hintIssue = iota
+	hintIssueDuplicate
+	hintIssueContradiction
+)
+
+// hintIssues holds the problems found in the current hints.
+type hintIssues struct {
+	contradictions map[string]struct{}            // locations
+	duplicates     map[string]map[string]struct{} // category name to hint keys
+}
+
+// flagsDuplicates returns true if hints entered more than once in the
+// category are reported and can be merged.
+func (category hintCategory) flagsDuplicates() bool {
+	switch category.Duplicates {
+	case hintDuplicatesConfirm, hintDuplicatesFlag:
+		return true
+	case hintDuplicatesMark, hintDuplicatesAllow:
+	}
+
+	return false
+}
+
+// validateHints looks for locations hinted both as required and barren and
+// for hints that were entered more than once.
+func (tracker *Tracker) validateHints() hintIssues {
+	issues := hintIssues{
+		contradictions: make(map[string]struct{}),
+		duplicates:     make(map[string]map[string]struct{}),
+	}
+
+	required := make(map[string]struct{})
+	for _, category := range tracker.cfg.HintTracker.Categories {
+		if category.flagsDuplicates() {
+			issues.duplicates[category.Name] = duplicates(tracker.hints[category.Name], hint.key)
+		}
+
+		if !category.Required {
+			continue
+		}
+
+		for _, v := range tracker.hints[category.Name] {
+			if v.Location != "" {
+				required[v.Location] = struct{}{}
+			}
+		}
+	}
+
+	for _, category := range tracker.cfg.HintTracker.Categories {
+		if !category.Barren {
+			continue
+		}
+
+		for _, v := range tracker.hints[category.Name] {
+			if _, ok := required[v.Location]; ok {
+				issues.contradictions[v.Location] = struct{}{}
+			}
+		}
+	}
+
+	return issues
+}
+
+func (issues hintIssues) hintIssue(category hintCategory, h hint) hintIssue {
+	if _, ok := issues.contradictions[h.Location]; ok && h.Location != "" {
+		return hintIssueContradiction
+	}
+
+	if _, ok := issues.duplicates[category.Name][h.key()]; ok {
+		return hintIssueDuplicate
+	}
+
+	return hintIssueNone
+}
+
+// duplicates returns the keys found more than once in the given slice.
+func duplicates[T any](values []T, key func(T) string) map[string]struct{} {
+	seen := make(map[string]struct{}, len(values))
+	ret := make(map[string]struct{})
+	for _, v := range values {
+		k := key(v)
+		if _, ok := seen[k]; ok {
+			ret[k] = struct{}{}
+		}
+		seen[k] = struct{}{}
+	}
+
+	return ret
+}
+
+// MergeDuplicateHints removes hints that were entered more than once.
+// Repeated hints of Confirm categories are kept once with a marker.
+func (tracker *Tracker) MergeDuplicateHints() {
+	for _, category := range tracker.cfg.HintTracker.Categories {
+		if !category.flagsDuplicates() || category.Match == hintMatchSlot {
+			continue
+		}
+
+		for {
+			index := tracker.findDuplicateHint(category)
+			if index < 0 {
+				break
+			}
+
+			entry := undoStackEntry{
+				IsHint:       true,
+				IsMerge:      true,
+				HintCategory: category.Name,
+				HintIndex:    index,
+			}
+			tracker.mergeHint(category, &entry)
+			tracker.appendEntryToUndoStack(entry)
+		}
+	}
+}
+
+// findDuplicateHint returns the index of the last hint of the given category
+// that repeats a previous one, or -1.
+func (tracker *Tracker) findDuplicateHint(category hintCategory) int {
+	hints := tracker.hints[category.Name]
+	keys := make([]string, len(hints))
+	for k, v := range hints {
+		keys[k] = v.key()
+	}
+
+	for i := len(keys) - 1; i > 0; i-- {
+		if slices.Index(keys[:i], keys[i]) >= 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mergeHint removes the duplicated hint at entry.HintIndex and stores what is
+// needed to undo it in the entry.
+func (tracker *Tracker) mergeHint(category hintCategory, entry *undoStackEntry) {
+	hints := tracker.hints[category.Name]
+	removed := hints[entry.HintIndex]
+	entry.Hint = &removed
+
+	if category.Duplicates == hintDuplicatesConfirm {
+		first := slices.IndexFunc(hints, func(v hint) bool { return v.key() == removed.key() })
+		entry.HintMarked = !hints[first].Marked
+		hints[first].Marked = true
+	}
+
+	tracker.hints[category.Name] = slices.Delete(hints, entry.HintIndex, entry.HintIndex+1)
+}
+
+// unmergeHint restores a hint removed by mergeHint.
+func (tracker *Tracker) unmergeHint(entry undoStackEntry) {
+	hints := tracker.hints[entry.HintCategory]
+	if entry.HintMarked {
+		first := slices.IndexFunc(hints, func(v hint) bool { return v.key() == entry.Hint.key() })
+		if first >= 0 {
+			hints[first].Marked = false
+		}
+	}
+
+	tracker.hints[entry.HintCategory] = slices.Insert(hints, entry.HintIndex, *entry.Hint)
+}